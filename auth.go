@@ -0,0 +1,174 @@
+package vmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthProvider sets credentials of every request sent to database, including retries
+type AuthProvider interface {
+	Authorize(ctx context.Context, req *http.Request) error
+}
+
+// AuthInvalidator is implemented by AuthProvider caching credentials, so cached ones are dropped, when database
+// rejects request authorized by them with 401 Unauthorized
+type AuthInvalidator interface {
+	Invalidate(req *http.Request)
+}
+
+// BasicAuth authenticates requests with username and password
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Authorize(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// BearerToken authenticates requests with static bearer token
+type BearerToken string
+
+func (t BearerToken) Authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// BearerTokenFile authenticates requests with bearer token read from file, like projected service account token
+// of Kubernetes. File is read again, when it is modified.
+type BearerTokenFile struct {
+	Path string
+
+	once  sync.Once
+	token *reloadingFile[string]
+}
+
+func (f *BearerTokenFile) Authorize(_ context.Context, req *http.Request) error {
+	f.once.Do(func() {
+		f.token = newReloadingFile(func() (string, error) {
+			raw, err := os.ReadFile(f.Path)
+			if err != nil {
+				return "", err
+			}
+			token := strings.TrimSpace(string(raw))
+			if token == "" {
+				return "", fmt.Errorf("token file %s is empty", f.Path)
+			}
+			return token, nil
+		}, f.Path)
+	})
+	token, err := f.token.get()
+	if err != nil {
+		return fmt.Errorf("error reading bearer token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// OAuth2ClientCredentials authenticates requests with access token received by OAuth2 client credentials grant,
+// as described here https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+// Token is cached and requested again shortly before it expires or after database rejects it as unauthorized.
+type OAuth2ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// EndpointParams are additional parameters of token request, like audience
+	EndpointParams url.Values
+	// HTTPClient is used for token requests, http.DefaultClient is used if nil
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (o *OAuth2ClientCredentials) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := o.getToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops cached token, if request was authorized by it, so new token is requested for next request
+func (o *OAuth2ClientCredentials) Invalidate(req *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token != "" && req.Header.Get("Authorization") == "Bearer "+o.token {
+		o.token = ""
+		o.expiry = time.Time{}
+	}
+}
+
+// getToken returns cached token, requesting new one, if it is about to expire
+func (o *OAuth2ClientCredentials) getToken(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token != "" && (o.expiry.IsZero() || time.Until(o.expiry) > DefaultTokenRefreshLeeway) {
+		return o.token, nil
+	}
+	args := url.Values{}
+	for k, v := range o.EndpointParams {
+		args[k] = v
+	}
+	args.Set("grant_type", "client_credentials")
+	if len(o.Scopes) > 0 {
+		args.Set("scope", strings.Join(o.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(args.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	hclient := o.HTTPClient
+	if hclient == nil {
+		hclient = http.DefaultClient
+	}
+	resp, err := hclient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting oauth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", Err{
+			Code:     resp.StatusCode,
+			Message:  "error requesting oauth2 token",
+			Response: string(body),
+			Err:      ErrUnexpectedResponse,
+		}
+	}
+	var parsed oauth2TokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&parsed)
+	if err != nil {
+		return "", fmt.Errorf("error parsing oauth2 token: %w", err)
+	}
+	if parsed.AccessToken == "" {
+		return "", errors.New("oauth2 token is empty")
+	}
+	o.token = parsed.AccessToken
+	o.expiry = time.Time{}
+	if parsed.ExpiresIn > 0 {
+		o.expiry = time.Now().Add(time.Duration(parsed.ExpiresIn) * time.Second)
+	}
+	return o.token, nil
+}
@@ -0,0 +1,138 @@
+package vmclient
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthAgainstHttpMock(t *testing.T) {
+	var expected atomic.Value
+	var tokensIssued atomic.Int32
+	authorized := func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") != expected.Load().(string) {
+			return httpmock.NewStringResponse(http.StatusUnauthorized, "unauthorized"), nil
+		}
+		if req.Method == http.MethodGet {
+			return httpmock.NewStringResponse(http.StatusOK, "OK"), nil
+		}
+		return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+	}
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy", authorized)
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultPushEndpoint, authorized)
+	mockTransport.RegisterResponder(http.MethodPost, "http://auth.example.org/token",
+		func(req *http.Request) (*http.Response, error) {
+			id, secret, ok := req.BasicAuth()
+			if !ok || id != "vmclient" || secret != "secret" || req.FormValue("grant_type") != "client_credentials" ||
+				req.FormValue("scope") != "read write" {
+				return httpmock.NewStringResponse(http.StatusUnauthorized, "wrong client credentials"), nil
+			}
+			tokensIssued.Add(1)
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"access_token":"issued","token_type":"Bearer","expires_in":3600}`), nil
+		})
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(tokenFile, []byte("first\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testCase := range map[string]struct {
+		auth     AuthProvider
+		expected string
+	}{
+		"basic":  {auth: BasicAuth{Username: "user", Password: "password"}, expected: "Basic dXNlcjpwYXNzd29yZA=="},
+		"bearer": {auth: BearerToken("static"), expected: "Bearer static"},
+		"file":   {auth: &BearerTokenFile{Path: tokenFile}, expected: "Bearer first"},
+		"oauth2": {
+			auth: &OAuth2ClientCredentials{
+				TokenURL:     "http://auth.example.org/token",
+				ClientID:     "vmclient",
+				ClientSecret: "secret",
+				Scopes:       []string{"read", "write"},
+				HTTPClient:   &http.Client{Transport: mockTransport},
+			},
+			expected: "Bearer issued",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			expected.Store(testCase.expected)
+			client, errC := New(t.Context(), Config{
+				Address:    DefaultEndpoint,
+				HttpClient: &http.Client{Transport: mockTransport},
+				Auth:       testCase.auth,
+			})
+			if errC != nil {
+				t.Errorf("error creating client: %s", errC)
+				return
+			}
+			set := metrics.NewSet()
+			set.GetOrCreateCounter(`something{job="vmclient"}`).Set(1)
+			assert.NoError(t, client.Push(t.Context(), set))
+		})
+	}
+	assert.Equal(t, int32(1), tokensIssued.Load(), "oauth2 token is not cached")
+
+	t.Run("rotated file", func(t *testing.T) {
+		provider := &BearerTokenFile{Path: tokenFile}
+		expected.Store("Bearer first")
+		client, errC := New(t.Context(), Config{
+			Address:    DefaultEndpoint,
+			HttpClient: &http.Client{Transport: mockTransport},
+			Auth:       provider,
+		})
+		if errC != nil {
+			t.Errorf("error creating client: %s", errC)
+			return
+		}
+		expected.Store("Bearer second")
+		errW := os.WriteFile(tokenFile, []byte("second"), 0o600)
+		if errW == nil {
+			errW = os.Chtimes(tokenFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+		}
+		if errW != nil {
+			t.Fatal(errW)
+		}
+		assert.NoError(t, client.Ping(t.Context()))
+	})
+
+	t.Run("revoked oauth2 token", func(t *testing.T) {
+		var issued atomic.Int32
+		revokingTransport := httpmock.NewMockTransport()
+		revokingTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy", authorized)
+		revokingTransport.RegisterResponder(http.MethodPost, "http://auth.example.org/token",
+			func(req *http.Request) (*http.Response, error) {
+				// token without expiration is cached until database rejects it
+				return httpmock.NewStringResponse(http.StatusOK,
+					fmt.Sprintf(`{"access_token":"issued-%d","token_type":"Bearer"}`, issued.Add(1))), nil
+			})
+		expected.Store("Bearer issued-1")
+		client, errC := New(t.Context(), Config{
+			Address:    DefaultEndpoint,
+			HttpClient: &http.Client{Transport: revokingTransport},
+			Auth: &OAuth2ClientCredentials{
+				TokenURL:   "http://auth.example.org/token",
+				HTTPClient: &http.Client{Transport: revokingTransport},
+			},
+		})
+		if errC != nil {
+			t.Errorf("error creating client: %s", errC)
+			return
+		}
+		assert.NoError(t, client.Ping(t.Context()))
+		expected.Store("Bearer issued-2")
+		assert.Error(t, client.Ping(t.Context()), "revoked token is accepted")
+		assert.NoError(t, client.Ping(t.Context()), "revoked token is not dropped")
+		assert.Equal(t, int32(2), issued.Load())
+	})
+}
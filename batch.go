@@ -0,0 +1,210 @@
+package vmclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what Enqueue does, when batching queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Enqueue wait until queue has free space or context is canceled
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop makes Enqueue drop samples and return ErrQueueFull
+	OverflowDrop
+)
+
+type queuedSample struct {
+	Sample
+	tenant Tenant
+}
+
+type batcher struct {
+	client   *Client
+	size     int
+	interval time.Duration
+	policy   OverflowPolicy
+	onError  func(samples []Sample, err error)
+	queue    chan queuedSample
+	mu       sync.RWMutex
+	closed   bool
+	// closing is closed, when close is called, so senders blocked on full queue release lock needed to close it
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+	dropped   atomic.Uint64
+}
+
+func newBatcher(c *Client, cfg Config) *batcher {
+	b := &batcher{
+		client:   c,
+		size:     cfg.BatchSize,
+		interval: cfg.BatchInterval,
+		policy:   cfg.BatchOverflow,
+		onError:  cfg.BatchErrorHandler,
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if b.interval <= 0 {
+		b.interval = DefaultBatchInterval
+	}
+	queueSize := cfg.BatchQueueSize
+	if queueSize < b.size {
+		queueSize = 10 * b.size
+	}
+	b.queue = make(chan queuedSample, queueSize)
+	go b.run()
+	return b
+}
+
+func (b *batcher) enqueue(ctx context.Context, tenant Tenant, samples []Sample) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	for i := range samples {
+		item := queuedSample{Sample: samples[i], tenant: tenant}
+		if b.policy == OverflowDrop {
+			select {
+			case b.queue <- item:
+				continue
+			default:
+				b.dropped.Add(uint64(len(samples) - i))
+				return ErrQueueFull
+			}
+		}
+		select {
+		case b.queue <- item:
+		case <-b.closing:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	batch := make([]queuedSample, 0, b.size)
+	for {
+		select {
+		case item, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= b.size {
+				b.flush(batch)
+				batch = make([]queuedSample, 0, b.size)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.flush(batch)
+				batch = make([]queuedSample, 0, b.size)
+			}
+		}
+	}
+}
+
+// flush sends batch grouping samples by tenant
+func (b *batcher) flush(batch []queuedSample) {
+	if len(batch) == 0 {
+		return
+	}
+	b.client.selfMetrics.flushed(len(batch))
+	byTenant := make(map[Tenant][]Sample)
+	var order []Tenant
+	for i := range batch {
+		if _, found := byTenant[batch[i].tenant]; !found {
+			order = append(order, batch[i].tenant)
+		}
+		byTenant[batch[i].tenant] = append(byTenant[batch[i].tenant], batch[i].Sample)
+	}
+	for _, tenant := range order {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultBatchFlushTimeout)
+		err := b.client.WithTenant(tenant).RemoteWrite(ctx, byTenant[tenant])
+		cancel()
+		if err != nil && b.onError != nil {
+			b.onError(byTenant[tenant], err)
+		}
+	}
+}
+
+// close stops accepting new samples and waits until queued ones are flushed
+func (b *batcher) close(ctx context.Context) error {
+	b.closeOnce.Do(func() { close(b.closing) })
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue adds samples to batch, which is sent in background by RemoteWrite, when Config.BatchSize samples
+// are collected or Config.BatchInterval passes. If batching is disabled, samples are sent immediately.
+func (c *Client) Enqueue(ctx context.Context, samples ...Sample) error {
+	if c.batcher == nil {
+		return c.RemoteWrite(ctx, samples)
+	}
+	return c.batcher.enqueue(ctx, c.tenant, samples)
+}
+
+// EnqueueGauge adds gauge with name like `something{job="vmclient"}` and current timestamp to batch
+func (c *Client) EnqueueGauge(ctx context.Context, name string, value float64) error {
+	labels, err := parseMetricName(name)
+	if err != nil {
+		return err
+	}
+	return c.Enqueue(ctx, Sample{Labels: labels, Timestamp: time.Now(), Value: value})
+}
+
+// EnqueueCounter adds counter with name like `something{job="vmclient"}` and current timestamp to batch
+func (c *Client) EnqueueCounter(ctx context.Context, name string, value uint64) error {
+	return c.EnqueueGauge(ctx, name, float64(value))
+}
+
+// Dropped returns number of samples dropped by Enqueue, because batching queue was full
+func (c *Client) Dropped() uint64 {
+	if c.batcher == nil {
+		return 0
+	}
+	return c.batcher.dropped.Load()
+}
+
+// parseMetricName parses name like `something{job="vmclient",unit="test"}` into labels
+func parseMetricName(input string) (labels map[string]string, err error) {
+	name, rest, found := strings.Cut(input, "{")
+	if !found {
+		labels = make(map[string]string)
+	} else {
+		if !strings.HasSuffix(rest, "}") {
+			return nil, fmt.Errorf("missing closing brace in %q", input)
+		}
+		labels, err = parseLabels(strings.TrimSuffix(rest, "}"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	name = strings.TrimSpace(name)
+	if name != "" {
+		labels[LabelForName] = name
+	}
+	return labels, nil
+}
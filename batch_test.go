@@ -0,0 +1,116 @@
+package vmclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetricName(t *testing.T) {
+	labels, err := parseMetricName(`something{job="vmclient",unit="test"}`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{LabelForName: "something", "job": "vmclient", "unit": "test"}, labels)
+	}
+	labels, err = parseMetricName("something")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{LabelForName: "something"}, labels)
+	}
+	_, err = parseMetricName(`something{job="vmclient"`)
+	assert.Error(t, err)
+}
+
+func TestBatchingAgainstHttpMock(t *testing.T) {
+	var writes atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultRemoteWriteEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			writes.Add(1)
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:       DefaultEndpoint,
+		HttpClient:    &http.Client{Transport: mockTransport},
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	for i := 0; i < 25; i++ {
+		err = client.EnqueueGauge(t.Context(), `something{job="vmclient"}`, float64(i))
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	assert.Eventually(t, func() bool {
+		return writes.Load() == 2
+	}, time.Second, 10*time.Millisecond, "full batches are not sent")
+	// derived client does not own batcher, so closing it does not stop batching of parent
+	assert.NoError(t, client.WithTenant(Tenant{AccountID: 1}).Close(t.Context()))
+	assert.NoError(t, client.EnqueueGauge(t.Context(), `something{job="vmclient"}`, 25))
+	assert.NoError(t, client.Close(t.Context()))
+	assert.Equal(t, int32(3), writes.Load(), "last batch is not sent on close")
+	assert.ErrorIs(t, client.EnqueueGauge(t.Context(), "something", 1), ErrClosed)
+}
+
+func TestBatchingCloseRespectsContext(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultRemoteWriteEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			// flush is slow, so queue is not drained
+			select {
+			case <-unblock:
+			case <-req.Context().Done():
+			}
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:        DefaultEndpoint,
+		HttpClient:     &http.Client{Transport: mockTransport},
+		BatchSize:      1,
+		BatchQueueSize: 1,
+		BatchInterval:  time.Hour,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	enqueued := make(chan error, 1)
+	go func() {
+		for {
+			errEnqueue := client.EnqueueGauge(t.Context(), "something", 1)
+			if errEnqueue != nil {
+				enqueued <- errEnqueue
+				return
+			}
+		}
+	}()
+	// wait until sender is blocked on full queue
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	assert.ErrorIs(t, client.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), time.Second, "close blocks past deadline of context")
+	select {
+	case err = <-enqueued:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(time.Second):
+		t.Error("blocked sender is not released by close")
+	}
+}
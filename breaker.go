@@ -0,0 +1,182 @@
+package vmclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CircuitState is state of circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed means requests are sent to database
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means requests fail fast with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen means database is being probed by Ping, while requests still fail fast
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerPolicy defines when requests to database fail fast, so overloaded database can recover
+type CircuitBreakerPolicy struct {
+	// FailureRatio is ratio of failed requests in Window, which opens circuit, zero disables circuit breaker.
+	// Requests failed due to network errors, responded with 5xx or 429 status codes or slower than SlowDuration are failed.
+	FailureRatio float64
+	// MinRequests is minimal number of requests in Window to open circuit, DefaultCircuitMinRequests is used if zero
+	MinRequests int
+	// Window is period, which requests are counted in, DefaultCircuitWindow is used if zero
+	Window time.Duration
+	// SlowDuration is duration of request considered failed, zero means latency is not considered
+	SlowDuration time.Duration
+	// OpenTimeout is delay after circuit is opened before database is probed by Ping, DefaultCircuitOpenTimeout is used if zero
+	OpenTimeout time.Duration
+}
+
+func (p CircuitBreakerPolicy) withDefaults() CircuitBreakerPolicy {
+	if p.MinRequests <= 0 {
+		p.MinRequests = DefaultCircuitMinRequests
+	}
+	if p.Window <= 0 {
+		p.Window = DefaultCircuitWindow
+	}
+	if p.OpenTimeout <= 0 {
+		p.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+	return p
+}
+
+type breaker struct {
+	policy CircuitBreakerPolicy
+	probe  func(ctx context.Context) error
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+}
+
+func newBreaker(policy CircuitBreakerPolicy, probe func(ctx context.Context) error) *breaker {
+	return &breaker{
+		policy:      policy.withDefaults(),
+		probe:       probe,
+		windowStart: time.Now(),
+	}
+}
+
+// allow returns error, if circuit is not closed. When open timeout passes, database is probed in background.
+func (b *breaker) allow(span trace.Span) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.policy.OpenTimeout {
+		b.state = CircuitHalfOpen
+		go b.runProbe()
+	}
+	if b.state == CircuitClosed {
+		return nil
+	}
+	span.AddEvent("circuit breaker rejected request", trace.WithAttributes(
+		attribute.String("circuit.state", b.state.String()),
+	))
+	return Err{
+		Message: "circuit breaker is " + b.state.String(),
+		Err:     ErrCircuitOpen,
+	}
+}
+
+// runProbe checks database by Ping and closes circuit, if it succeeds
+func (b *breaker) runProbe() {
+	ctx, cancel := context.WithTimeout(context.Background(), b.policy.OpenTimeout)
+	defer cancel()
+	err := b.probe(ctx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		return
+	}
+	b.state = CircuitClosed
+	b.windowStart = time.Now()
+	b.requests = 0
+	b.failures = 0
+}
+
+// record counts result of request performed, opening circuit, if too many requests failed
+func (b *breaker) record(span trace.Span, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != CircuitClosed {
+		return
+	}
+	if time.Since(b.windowStart) >= b.policy.Window {
+		b.windowStart = time.Now()
+		b.requests = 0
+		b.failures = 0
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.requests >= b.policy.MinRequests && float64(b.failures)/float64(b.requests) >= b.policy.FailureRatio {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		span.AddEvent("circuit breaker opened", trace.WithAttributes(
+			attribute.Int("circuit.requests", b.requests),
+			attribute.Int("circuit.failures", b.failures),
+		))
+	}
+}
+
+// CircuitState returns state of circuit breaker, it is always closed, if circuit breaker is not configured
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.state
+}
+
+// sendGuarded performs request through circuit breaker, if it is configured
+func (c *Client) sendGuarded(operation string, req *http.Request) (resp *http.Response, err error) {
+	if c.breaker == nil {
+		return c.send(operation, req)
+	}
+	span := trace.SpanFromContext(req.Context())
+	err = c.breaker.allow(span)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	resp, err = c.send(operation, req)
+	if req.Context().Err() != nil {
+		// requests canceled by caller tell nothing about database health
+		return resp, err
+	}
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	if c.breaker.policy.SlowDuration > 0 && time.Since(started) > c.breaker.policy.SlowDuration {
+		failed = true
+	}
+	c.breaker.record(span, failed)
+	return resp, err
+}
@@ -0,0 +1,75 @@
+package vmclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerAgainstHttpMock(t *testing.T) {
+	var overloaded atomic.Bool
+	var queried atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		func(req *http.Request) (*http.Response, error) {
+			if overloaded.Load() {
+				return httpmock.NewStringResponse(http.StatusServiceUnavailable, "overloaded"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, "OK"), nil
+		})
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query`,
+		func(req *http.Request) (*http.Response, error) {
+			queried.Add(1)
+			if overloaded.Load() {
+				return httpmock.NewStringResponse(http.StatusServiceUnavailable, "overloaded"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+		CircuitBreaker: CircuitBreakerPolicy{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+			OpenTimeout:  50 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+
+	overloaded.Store(true)
+	for i := 0; i < 4; i++ {
+		_, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		assert.ErrorIs(t, errQ, ErrUnexpectedResponse)
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitState())
+
+	_, err = client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var properOne Err
+	assert.ErrorAs(t, err, &properOne)
+	assert.Equal(t, int32(4), queried.Load(), "request is sent, when circuit is open")
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Eventually(t, func() bool { return client.CircuitState() == CircuitOpen }, time.Second,
+		5*time.Millisecond, "circuit is not opened again, when probe fails")
+
+	overloaded.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Eventually(t, func() bool { return client.CircuitState() == CircuitClosed }, time.Second,
+		5*time.Millisecond, "circuit is not closed, when probe succeeds")
+	_, err = client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,188 @@
+package vmclient
+
+import (
+	"container/list"
+	"context"
+	"maps"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cacheEntry is result of range query covering [start, end] with timestamps aligned to step
+type cacheEntry struct {
+	key     string
+	start   time.Time
+	end     time.Time
+	data    []Range
+	size    int64
+	created time.Time
+}
+
+// rangeCache keeps results of range queries, evicting least recently used ones, when size limit is exceeded
+type rangeCache struct {
+	maxBytes int64
+	ttl      time.Duration
+	offset   time.Duration
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+func newRangeCache(maxBytes int64, ttl, offset time.Duration) *rangeCache {
+	return &rangeCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		offset:   offset,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get returns entry stored by key, if it is not expired
+func (rc *rangeCache) get(key string) (entry *cacheEntry, found bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	element, found := rc.entries[key]
+	if !found {
+		return nil, false
+	}
+	entry = element.Value.(*cacheEntry)
+	if time.Since(entry.created) > rc.ttl {
+		rc.removeElement(element)
+		return nil, false
+	}
+	rc.lru.MoveToFront(element)
+	return entry, true
+}
+
+// put stores entry, replacing previous one with same key, and evicts least recently used entries
+func (rc *rangeCache) put(entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	element, found := rc.entries[entry.key]
+	if found {
+		rc.removeElement(element)
+	}
+	if entry.size > rc.maxBytes {
+		return
+	}
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+	rc.size += entry.size
+	for rc.size > rc.maxBytes {
+		rc.removeElement(rc.lru.Back())
+	}
+}
+
+func (rc *rangeCache) removeElement(element *list.Element) {
+	entry := rc.lru.Remove(element).(*cacheEntry)
+	delete(rc.entries, entry.key)
+	rc.size -= entry.size
+}
+
+// rangeSize estimates memory used by data
+func rangeSize(data []Range) (size int64) {
+	for i := range data {
+		size += 64
+		for k, v := range data[i].Labels {
+			size += int64(len(k) + len(v) + 32)
+		}
+		size += int64(len(data[i].Values)) * 32
+	}
+	return size
+}
+
+// trimRanges returns copy of data with points in [start, end] only, series without points are dropped
+func trimRanges(data []Range, start, end time.Time) (trimmed []Range) {
+	for i := range data {
+		var values []Result
+		for j := range data[i].Values {
+			ts := data[i].Values[j].Timestamp
+			if ts.Before(start) || ts.After(end) {
+				continue
+			}
+			values = append(values, data[i].Values[j])
+		}
+		if len(values) > 0 {
+			trimmed = append(trimmed, Range{Labels: maps.Clone(data[i].Labels), Values: values})
+		}
+	}
+	return trimmed
+}
+
+// alignToStep rounds timestamp down to multiple of step, like Victoria Metrics does for cached range queries.
+// Timestamp is returned as is, if step is shorter than millisecond.
+func alignToStep(ts time.Time, step time.Duration) time.Time {
+	if step < time.Millisecond {
+		return ts
+	}
+	ms := ts.UnixMilli()
+	return time.UnixMilli(ms - ms%step.Milliseconds())
+}
+
+// cacheKey identifies range query by tenant, query, step and options
+func (c *Client) cacheKey(params doParams) string {
+	args := url.Values{}
+	params.opts.apply(args, trace.SpanFromContext(context.Background()), c.redaction)
+	return c.tenant.String() + "\x00" + params.query + "\x00" + params.step.String() + "\x00" + args.Encode()
+}
+
+// rangeCached performs range query, using cached result of previous one and fetching only missing tail of it.
+// Points newer than cache timestamp offset are not cached, since they can be not ingested yet.
+func (c *Client) rangeCached(ctx context.Context, span trace.Span, params doParams) (data []Range, err error) {
+	params.start = alignToStep(params.start, params.step)
+	params.end = alignToStep(params.end, params.step)
+	key := c.cacheKey(params)
+	cutoff := alignToStep(time.Now().Add(-c.cache.offset), params.step)
+
+	entry, found := c.cache.get(key)
+	if found && !entry.start.After(params.start) && !entry.end.Before(params.start) {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		data = entry.data
+		if entry.end.Before(params.end) {
+			tail := params
+			tail.start = entry.end.Add(params.step)
+			span.AddEvent("fetching missing tail", trace.WithAttributes(
+				attribute.String("start", tail.start.Format(time.RFC3339)),
+			))
+			var fetched []Range
+			fetched, err = c.rangeFetch(ctx, span, tail)
+			if err != nil {
+				return nil, err
+			}
+			data = mergeRanges([][]Range{data, fetched})
+		}
+	} else {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		data, err = c.rangeFetch(ctx, span, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cachedEnd := params.end
+	if cachedEnd.After(cutoff) {
+		cachedEnd = cutoff
+	}
+	if !cachedEnd.Before(params.start) {
+		cached := trimRanges(data, params.start, cachedEnd)
+		created := time.Now()
+		if found {
+			created = entry.created
+		}
+		c.cache.put(&cacheEntry{
+			key:     key,
+			start:   params.start,
+			end:     cachedEnd,
+			data:    cached,
+			size:    rangeSize(cached),
+			created: created,
+		})
+	}
+	return trimRanges(data, params.start, params.end), nil
+}
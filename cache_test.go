@@ -0,0 +1,93 @@
+package vmclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeCache(t *testing.T) {
+	data := []Range{{Labels: map[string]string{"a": "b"}, Values: []Result{{Value: 1}, {Value: 2}}}}
+	size := rangeSize(data)
+	rc := newRangeCache(size*5/2, time.Minute, 0)
+	rc.put(&cacheEntry{key: "first", data: data, size: size, created: time.Now()})
+	rc.put(&cacheEntry{key: "second", data: data, size: size, created: time.Now()})
+	_, found := rc.get("first")
+	assert.True(t, found)
+	rc.put(&cacheEntry{key: "third", data: data, size: size, created: time.Now()})
+	_, found = rc.get("second")
+	assert.False(t, found, "least recently used entry is not evicted")
+	_, found = rc.get("first")
+	assert.True(t, found)
+
+	rc.put(&cacheEntry{key: "expired", data: data, size: size, created: time.Now().Add(-time.Hour)})
+	_, found = rc.get("expired")
+	assert.False(t, found, "expired entry is returned")
+}
+
+func TestRangeCacheAgainstHttpMock(t *testing.T) {
+	var requests atomic.Int32
+	var lastStart atomic.Int64
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/query_range",
+		func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			start, _ := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+			end, _ := strconv.ParseInt(req.URL.Query().Get("end"), 10, 64)
+			lastStart.Store(start)
+			var values []string
+			for ts := start; ts <= end; ts += 30 {
+				values = append(values, fmt.Sprintf("[%v,\"%v\"]", ts, ts))
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"something"},"values":[`+
+					strings.Join(values, ",")+`]}]}}`), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:              DefaultEndpoint,
+		HttpClient:           &http.Client{Transport: mockTransport},
+		CacheMaxBytes:        1 << 20,
+		CacheTimestampOffset: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	now := alignToStep(time.Now(), 30*time.Second)
+	lines, err := client.Range(t.Context(), "something", now.Add(-6*time.Hour), now, 30*time.Second)
+	if assert.NoError(t, err) && assert.Len(t, lines, 1) {
+		assert.Len(t, lines[0].Values, 721)
+	}
+
+	lines, err = client.Range(t.Context(), "something", now.Add(-6*time.Hour+30*time.Second), now.Add(30*time.Second),
+		30*time.Second)
+	if assert.NoError(t, err) && assert.Len(t, lines, 1) {
+		assert.Len(t, lines[0].Values, 721)
+		assert.Equal(t, now.Add(-6*time.Hour+30*time.Second), lines[0].Values[0].Timestamp)
+		assert.Equal(t, now.Add(30*time.Second), lines[0].Values[720].Timestamp)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, now.Add(-5*time.Minute+30*time.Second).Unix(), lastStart.Load(), "cached points are fetched again")
+
+	_, err = client.Range(t.Context(), "something", now.Add(-6*time.Hour), now, 30*time.Second, WithCacheBypass())
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-6*time.Hour).Unix(), lastStart.Load(), "cache is not bypassed")
+
+	requested := requests.Load()
+	assert.NotPanics(t, func() {
+		_, err = client.Range(t.Context(), "something", now.Add(-time.Minute), now, time.Microsecond)
+	}, "step shorter than millisecond is aligned")
+	assert.NoError(t, err)
+	assert.Equal(t, requested+1, requests.Load())
+	assert.Equal(t, time.Unix(123, 456789), alignToStep(time.Unix(123, 456789), time.Microsecond))
+}
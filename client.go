@@ -2,41 +2,208 @@ package vmclient
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client struct {
-	endpoint    string
-	headers     map[string]string
-	hclient     *http.Client
-	extraLabels string
+	endpoint       string
+	insertEndpoint string
+	cluster        bool
+	tenant         Tenant
+	headers        map[string]string
+	hclient        *http.Client
+	extraLabels    string
+	extraLabelSet  map[string]string
+
+	maxPointsPerRequest int
+	splitConcurrency    int
+	postThreshold       int
+	forcePost           bool
+	retry               RetryPolicy
+	hedgeDelay          time.Duration
+	breaker             *breaker
+	queryLimiter        *limiter
+	pushLimiter         *limiter
+	cache               *rangeCache
+	responseLimits      responseLimits
+	compression         Compression
+	auth                AuthProvider
+	redaction           RedactionPolicy
+	tracer              trace.Tracer
+	telemetry           *telemetry
+	selfMetrics         *selfMetrics
+
+	batcher    *batcher
+	queue      *diskQueue
+	stopReplay context.CancelFunc
+	replayDone chan struct{}
+	pool       *endpointPool
+	stopHealth context.CancelFunc
+	healthDone chan struct{}
+	// derived is true for clients made by WithTenant, which do not own background workers of parent
+	derived bool
 }
 
-func (c *Client) Close(context.Context) (err error) {
+// Close waits until samples enqueued are sent, stops replaying disk queue and health checks, unregisters
+// gauges of queues from Config.Metrics and closes idle connections. Client made by WithTenant only closes idle connections, since workers are owned by parent one.
+func (c *Client) Close(ctx context.Context) (err error) {
+	if c.derived {
+		c.hclient.CloseIdleConnections()
+		return nil
+	}
+	if c.batcher != nil {
+		err = c.batcher.close(ctx)
+	}
+	if c.stopReplay != nil {
+		c.stopReplay()
+		select {
+		case <-c.replayDone:
+		case <-ctx.Done():
+			err = errors.Join(err, ctx.Err())
+		}
+	}
+	if c.stopHealth != nil {
+		c.stopHealth()
+		select {
+		case <-c.healthDone:
+		case <-ctx.Done():
+			err = errors.Join(err, ctx.Err())
+		}
+	}
+	c.selfMetrics.unregisterQueues()
 	c.hclient.CloseIdleConnections()
-	return nil
+	return err
 }
 
 func New(ctx context.Context, cfg Config) (vmc *Client, err error) {
 	vmc = &Client{
-		endpoint:    cfg.Address,
-		headers:     cfg.Headers,
-		extraLabels: cfg.ExtraLabels,
+		endpoint:       cfg.Address,
+		insertEndpoint: cfg.Address,
+		headers:        cfg.Headers,
+		extraLabels:    cfg.ExtraLabels,
+
+		maxPointsPerRequest: cfg.MaxPointsPerRequest,
+		splitConcurrency:    cfg.SplitConcurrency,
+		postThreshold:       cfg.PostThreshold,
+		forcePost:           cfg.ForcePost,
+		retry:               cfg.Retry.withDefaults(),
+		hedgeDelay:          cfg.HedgeDelay,
+		queryLimiter:        newLimiter(cfg.QueryLimits),
+		pushLimiter:         newLimiter(cfg.PushLimits),
+		compression:         cfg.Compression,
+		auth:                cfg.Auth,
+		redaction:           cfg.Redaction.withDefaults(),
+		selfMetrics:         newSelfMetrics(cfg.Metrics),
+		responseLimits: responseLimits{
+			maxBytes:  cfg.MaxResponseBytes,
+			maxSeries: cfg.MaxResponseSeries,
+			maxPoints: cfg.MaxResponsePoints,
+		},
+	}
+	tracerProvider := cfg.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	vmc.tracer = tracerProvider.Tracer(instrumentationName)
+	meterProvider := cfg.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	vmc.telemetry, err = newTelemetry(meterProvider)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metrics: %w", err)
+	}
+	vmc.extraLabelSet, err = parseLabels(cfg.ExtraLabels)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing extra labels: %w", err)
 	}
-	if cfg.Insecure {
-		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	if cfg.SelectAddress != "" || cfg.InsertAddress != "" {
+		vmc.cluster = true
+		vmc.endpoint = cfg.SelectAddress
+		vmc.insertEndpoint = cfg.InsertAddress
+		vmc.tenant = cfg.Tenant
+	}
+	if cfg.CacheMaxBytes > 0 {
+		ttl := cfg.CacheTTL
+		if ttl <= 0 {
+			ttl = DefaultCacheTTL
+		}
+		offset := cfg.CacheTimestampOffset
+		if offset <= 0 {
+			offset = DefaultCacheTimestampOffset
+		}
+		vmc.cache = newRangeCache(cfg.CacheMaxBytes, ttl, offset)
+	}
+	if cfg.CircuitBreaker.FailureRatio > 0 {
+		vmc.breaker = newBreaker(cfg.CircuitBreaker, vmc.Ping)
+	}
+	if len(cfg.Addresses) > 0 {
+		vmc.pool = newEndpointPool(cfg.Addresses, cfg.Balancing)
+		vmc.endpoint = cfg.Addresses[0]
+		if !vmc.cluster && cfg.Address == "" {
+			vmc.insertEndpoint = cfg.Addresses[0]
+		}
+	}
+	if vmc.postThreshold == 0 {
+		vmc.postThreshold = DefaultPostThreshold
 	}
 	if cfg.HttpClient != nil {
 		vmc.hclient = cfg.HttpClient
 	} else {
-		vmc.hclient = otelhttp.DefaultClient
+		tlsConfig := cfg.TLS
+		if cfg.Insecure {
+			tlsConfig.InsecureSkipVerify = true
+		}
+		vmc.hclient, err = newHTTPClient(tlsConfig, vmc.redaction,
+			otelhttp.WithTracerProvider(tracerProvider), otelhttp.WithMeterProvider(meterProvider))
+		if err != nil {
+			return nil, fmt.Errorf("error configuring TLS: %w", err)
+		}
 	}
-	err = vmc.Ping(ctx)
+	err = vmc.selfMetrics.checkQueues(cfg.BatchSize > 0, cfg.QueuePath != "")
 	if err != nil {
 		return nil, err
 	}
+	if cfg.QueuePath != "" {
+		vmc.queue, err = openDiskQueue(cfg.QueuePath, cfg.QueueMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = vmc.Ping(ctx)
+	if err != nil && vmc.queue == nil {
+		return nil, err
+	}
+	if vmc.queue != nil {
+		interval := cfg.QueueReplayInterval
+		if interval <= 0 {
+			interval = DefaultQueueReplayInterval
+		}
+		var replayCtx context.Context
+		replayCtx, vmc.stopReplay = context.WithCancel(context.Background())
+		vmc.replayDone = make(chan struct{})
+		go vmc.replayQueue(replayCtx, vmc.replayDone, interval)
+	}
+	if vmc.pool != nil {
+		interval := cfg.HealthCheckInterval
+		if interval <= 0 {
+			interval = DefaultHealthCheckInterval
+		}
+		var healthCtx context.Context
+		healthCtx, vmc.stopHealth = context.WithCancel(context.Background())
+		vmc.healthDone = make(chan struct{})
+		go vmc.watchHealth(healthCtx, vmc.healthDone, interval)
+	}
+	if cfg.BatchSize > 0 {
+		vmc.batcher = newBatcher(vmc, cfg)
+	}
+	vmc.selfMetrics.registerQueues(vmc.batcher, vmc.queue)
 	return vmc, nil
 }
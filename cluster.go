@@ -0,0 +1,98 @@
+package vmclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Tenant identifies tenant of Victoria Metrics cluster, as described here
+// https://docs.victoriametrics.com/victoriametrics/cluster-victoriametrics/#multitenancy
+type Tenant struct {
+	AccountID uint32
+	ProjectID uint32
+}
+
+func (t Tenant) String() string {
+	return fmt.Sprintf("%d:%d", t.AccountID, t.ProjectID)
+}
+
+// WithTenant returns client for other tenant of Victoria Metrics cluster, sharing connection pool, settings,
+// batching, disk queue and health checks with parent one. Closing derived client only closes idle connections
+// of shared pool, while background workers are stopped, when parent client is closed.
+func (c *Client) WithTenant(tenant Tenant) *Client {
+	derived := *c
+	derived.tenant = tenant
+	derived.derived = true
+	return &derived
+}
+
+// Tenant returns tenant, which client is reading and writing data of
+func (c *Client) Tenant() Tenant {
+	return c.tenant
+}
+
+// selectURL returns url of query API - of single node Victoria Metrics or of vmselect of cluster,
+// as described here https://docs.victoriametrics.com/victoriametrics/cluster-victoriametrics/#url-format
+func (c *Client) selectURL(address string, elem ...string) (u *url.URL, err error) {
+	if address == "" {
+		return nil, fmt.Errorf("%w: select address is empty", ErrNoEndpoint)
+	}
+	u, err = parseEndpoint(address)
+	if err != nil {
+		return nil, err
+	}
+	if c.cluster {
+		return u.JoinPath(append([]string{"select", c.tenant.String(), "prometheus"}, elem...)...), nil
+	}
+	return u.JoinPath(append([]string{"prometheus"}, elem...)...), nil
+}
+
+// insertURL returns url of ingestion API - of single node Victoria Metrics or of vminsert of cluster
+func (c *Client) insertURL(elem ...string) (u *url.URL, err error) {
+	if c.insertEndpoint == "" {
+		return nil, fmt.Errorf("%w: insert address is empty", ErrNoEndpoint)
+	}
+	u, err = parseEndpoint(c.insertEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if c.cluster {
+		return u.JoinPath(append([]string{"insert", c.tenant.String(), "prometheus"}, elem...)...), nil
+	}
+	return u.JoinPath(elem...), nil
+}
+
+// parseEndpoint parses address, making its path absolute, so paths joined to it are absolute too
+func parseEndpoint(address string) (u *url.URL, err error) {
+	u, err = url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing endpoint: %s", err)
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u, nil
+}
+
+// healthEndpoints lists addresses to be checked by Ping, except ones of endpoint pool
+func (c *Client) healthEndpoints() (addresses []string) {
+	if c.pool != nil {
+		for _, ep := range c.pool.endpoints {
+			if ep.address == c.insertEndpoint {
+				return nil
+			}
+		}
+		if c.insertEndpoint != "" {
+			addresses = append(addresses, c.insertEndpoint)
+		}
+		return addresses
+	}
+	if c.endpoint != "" {
+		addresses = append(addresses, c.endpoint)
+	}
+	if c.insertEndpoint != "" && c.insertEndpoint != c.endpoint {
+		addresses = append(addresses, c.insertEndpoint)
+	}
+	return addresses
+}
@@ -0,0 +1,67 @@
+package vmclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterAgainstHttpMock(tt *testing.T) {
+	const selectAddress = "http://vmselect:8481"
+	const insertAddress = "http://vminsert:8480"
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, selectAddress+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, insertAddress+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, selectAddress+"/select/42:1/prometheus/api/v1/query",
+		httpmock.NewStringResponder(http.StatusOK,
+			`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"42"]}}`))
+	mockTransport.RegisterResponder(http.MethodGet, selectAddress+"/select/43:0/prometheus/api/v1/query",
+		httpmock.NewStringResponder(http.StatusOK,
+			`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"43"]}}`))
+
+	client, err := New(tt.Context(), Config{
+		SelectAddress: selectAddress,
+		InsertAddress: insertAddress,
+		Tenant:        Tenant{AccountID: 42, ProjectID: 1},
+		HttpClient:    &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		tt.Errorf("error creating client: %s", err)
+		return
+	}
+
+	tt.Run("ping both", func(t *testing.T) {
+		assert.NoError(t, client.Ping(t.Context()))
+		// first ping is made by New
+		assert.Equal(t, 2, mockTransport.GetCallCountInfo()["GET "+insertAddress+"/-/healthy"])
+	})
+
+	tt.Run("urls", func(t *testing.T) {
+		u, errU := client.insertURL(DefaultPushEndpoint)
+		if assert.NoError(t, errU) {
+			assert.Equal(t, insertAddress+"/insert/42:1/prometheus/api/v1/import/prometheus", u.String())
+		}
+	})
+
+	tt.Run("query", func(t *testing.T) {
+		result, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		if assert.NoError(t, errQ) {
+			assert.Equal(t, float64(42), result.Scalar.Value)
+		}
+	})
+
+	tt.Run("other tenant", func(t *testing.T) {
+		other := client.WithTenant(Tenant{AccountID: 43})
+		assert.Equal(t, "43:0", other.Tenant().String())
+		assert.Equal(t, "42:1", client.Tenant().String())
+		result, errQ := other.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		if assert.NoError(t, errQ) {
+			assert.Equal(t, float64(43), result.Scalar.Value)
+		}
+	})
+}
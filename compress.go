@@ -0,0 +1,182 @@
+package vmclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Compression is encoding of request bodies sent and responses requested
+type Compression string
+
+const (
+	// CompressionDefault means pushed metrics are compressed by gzip, remote write requests - by snappy,
+	// while imported series and query responses are not compressed
+	CompressionDefault Compression = ""
+	// CompressionGzip means bodies are compressed by gzip, remote write requests are still compressed by snappy,
+	// as protocol requires
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd means bodies are compressed by zstd, which is supported by Victoria Metrics
+	// for remote write requests too
+	CompressionZstd Compression = "zstd"
+)
+
+// zstdEncoder is shared, since it is safe to call EncodeAll concurrently
+var zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+	encoder, _ := zstd.NewWriter(nil)
+	return encoder
+})
+
+// acceptEncoding returns value of Accept-Encoding header of queries, zstd is preferred, but gzip is accepted too,
+// since older versions of Victoria Metrics do not support zstd
+func (c Compression) acceptEncoding() string {
+	switch c {
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd, gzip"
+	default:
+		return ""
+	}
+}
+
+// bodyEncoding returns encoding of pushed metrics and imported series
+func (c Compression) bodyEncoding(fallback string) string {
+	if c == CompressionDefault {
+		return fallback
+	}
+	return string(c)
+}
+
+// compress encodes body by gzip or zstd, other encodings are not supported
+func compress(encoding string, raw []byte) ([]byte, error) {
+	switch encoding {
+	case "gzip":
+		buf := bytes.Buffer{}
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(raw)
+		if err != nil {
+			return nil, err
+		}
+		err = zw.Close()
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "zstd":
+		return zstdEncoder().EncodeAll(raw, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %s", encoding)
+	}
+}
+
+// compressingWriter returns writer, that encodes data by gzip or zstd. Identity encoding is supported too.
+func compressingWriter(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case "":
+		return nopWriteCloser{w}, nil
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %s", encoding)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// countingWriter counts bytes written
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// countedReader counts bytes read
+type countedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countedReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// decompressedBody decodes compressed response and records its sizes on span, when it is closed
+type decompressedBody struct {
+	raw     io.ReadCloser
+	decoded io.Reader
+	closer  func()
+	span    trace.Span
+
+	compressed   countedReader
+	uncompressed int64
+}
+
+func (b *decompressedBody) Read(p []byte) (n int, err error) {
+	n, err = b.decoded.Read(p)
+	b.uncompressed += int64(n)
+	return n, err
+}
+
+func (b *decompressedBody) Close() error {
+	if b.closer != nil {
+		b.closer()
+	}
+	b.span.SetAttributes(
+		attribute.Int64("response.body.compressed", b.compressed.n),
+		attribute.Int64("response.body.uncompressed", b.uncompressed),
+	)
+	return b.raw.Close()
+}
+
+// decompressResponse replaces body of response compressed by gzip or zstd with decoded one
+func decompressResponse(resp *http.Response, span trace.Span) error {
+	encoding := resp.Header.Get("Content-Encoding")
+	if encoding != "gzip" && encoding != "zstd" {
+		return nil
+	}
+	body := &decompressedBody{raw: resp.Body, span: span}
+	body.compressed.r = resp.Body
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(&body.compressed)
+		if err != nil {
+			return fmt.Errorf("error decoding gzip response: %w", err)
+		}
+		body.decoded = zr
+	case "zstd":
+		zr, err := zstd.NewReader(&body.compressed, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return fmt.Errorf("error decoding zstd response: %w", err)
+		}
+		body.decoded = zr
+		body.closer = zr.Close
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
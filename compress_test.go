@@ -0,0 +1,114 @@
+package vmclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/jarcoal/httpmock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCompressionAgainstHttpMock(t *testing.T) {
+	const response = `{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`
+	var pushed, imported []string
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query\?`,
+		func(req *http.Request) (*http.Response, error) {
+			accepted := req.Header.Get("Accept-Encoding")
+			if !strings.HasPrefix(accepted, "zstd") {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "zstd is not accepted: "+accepted), nil
+			}
+			resp := httpmock.NewBytesResponse(http.StatusOK, zstdEncoder().EncodeAll([]byte(response), nil))
+			resp.Header.Set("Content-Encoding", "zstd")
+			return resp, nil
+		})
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultPushEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Content-Encoding") != "zstd" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "body is not compressed by zstd"), nil
+			}
+			zr, err := zstd.NewReader(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				return nil, err
+			}
+			pushed = append(pushed, strings.TrimSpace(string(body)))
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultImportEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Content-Encoding") != "zstd" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "body is not compressed by zstd"), nil
+			}
+			zr, err := zstd.NewReader(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				return nil, err
+			}
+			imported = append(imported, strings.TrimSpace(string(body)))
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:     DefaultEndpoint,
+		HttpClient:  &http.Client{Transport: mockTransport},
+		Compression: CompressionZstd,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	result, err := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float64(1), result.Scalar.Value)
+	}
+
+	set := metrics.NewSet()
+	set.GetOrCreateCounter(`something{job="vmclient"}`).Set(1)
+	assert.NoError(t, client.Push(t.Context(), set))
+	assert.Equal(t, []string{`something{job="vmclient"} 1`}, pushed)
+
+	assert.NoError(t, client.PushSeries(t.Context(), Range{
+		Labels: map[string]string{"__name__": "something"},
+		Values: []Result{{Value: 1, Timestamp: time.UnixMilli(1734677495161)}},
+	}))
+	assert.Equal(t, []string{`{"metric":{"__name__":"something"},"values":[1],"timestamps":[1734677495161]}`}, imported)
+}
+
+func TestDecompressResponse(t *testing.T) {
+	buf := bytes.Buffer{}
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("compressed by gzip"))
+	zw.Close()
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   io.NopCloser(&buf),
+	}
+	if !assert.NoError(t, decompressResponse(resp, trace.SpanFromContext(t.Context()))) {
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "compressed by gzip", string(body))
+	}
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	assert.NoError(t, resp.Body.Close())
+}
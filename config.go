@@ -1,12 +1,114 @@
 package vmclient
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // Config defines connection parameters
 type Config struct {
 	Address     string
 	Headers     map[string]string
 	ExtraLabels string
-	HttpClient  *http.Client
-	Insecure    bool
+	// HttpClient is used for requests instead of client with dedicated transport configured by TLS
+	HttpClient *http.Client
+	// Insecure disables verification of server certificate, like TLS.InsecureSkipVerify does
+	Insecure bool
+	// Auth sets credentials of every request, like BasicAuth, BearerToken, BearerTokenFile or OAuth2ClientCredentials
+	Auth AuthProvider
+	// TracerProvider creates spans of requests, global provider is used if nil
+	TracerProvider trace.TracerProvider
+	// MeterProvider records metrics of operations, like db.client.operation.duration, global provider is used if nil
+	MeterProvider metric.MeterProvider
+	// Metrics is set, where client registers metrics of its requests, like vmclient_requests_total, latencies,
+	// bytes sent and received, sizes of batches, depths of queues and retries. Metrics are not reported if nil.
+	// Clients with batching or disk queue can not share set, since gauges of queues are unregistered only by Close.
+	Metrics *metrics.Set
+	// Redaction defines, which headers and query texts are sensitive, so they are not recorded in span attributes
+	// as is. Values of Authorization, Cookie and headers with "token" in name are masked by default.
+	Redaction RedactionPolicy
+	// TLS defines TLS settings of dedicated transport of client, it is ignored if HttpClient is set
+	TLS TLSConfig
+	// MaxPointsPerRequest enables splitting of Range queries into step-aligned chunks, so each chunk
+	// returns no more than this number of points per time series. Zero disables splitting.
+	MaxPointsPerRequest int
+	// SplitConcurrency limits number of chunks queried simultaneously, DefaultSplitConcurrency is used if zero.
+	SplitConcurrency int
+	// PostThreshold is length of url-encoded query arguments, above which query is sent as
+	// application/x-www-form-urlencoded POST body instead of GET URL. DefaultPostThreshold is used if zero,
+	// negative value disables POST queries.
+	PostThreshold int
+	// ForcePost makes all queries to be sent as POST requests
+	ForcePost bool
+	// SelectAddress is address of vmselect of Victoria Metrics cluster, like http://vmselect:8481.
+	// Setting SelectAddress or InsertAddress enables cluster mode, where Address is ignored.
+	SelectAddress string
+	// InsertAddress is address of vminsert of Victoria Metrics cluster, like http://vminsert:8480
+	InsertAddress string
+	// Tenant is tenant of Victoria Metrics cluster to read and write data of
+	Tenant Tenant
+	// Addresses are replicated single node Victoria Metrics instances or vmselect nodes of cluster, which read requests
+	// are balanced between. Address or SelectAddress are ignored for reads, if Addresses are set.
+	// Writes are sent to Address or InsertAddress, or to first of Addresses, if they are empty.
+	Addresses []string
+	// Balancing defines how endpoint for read request is picked from Addresses
+	Balancing BalancingPolicy
+	// HealthCheckInterval is interval between checks of Addresses health, DefaultHealthCheckInterval is used if zero
+	HealthCheckInterval time.Duration
+	// HedgeDelay enables hedging of Instant and Range queries - if query is not answered within this delay,
+	// its duplicate is sent to other endpoint of Addresses or by other connection, and first successful
+	// response is used. Zero disables hedging.
+	HedgeDelay time.Duration
+	// CircuitBreaker defines when requests fail fast with ErrCircuitOpen, so overloaded database can recover.
+	// It is disabled, if FailureRatio is zero.
+	CircuitBreaker CircuitBreakerPolicy
+	// QueryLimits restricts rate and concurrency of Instant and Range queries, so they do not exceed
+	// -search.maxConcurrentRequests of database shared with other clients
+	QueryLimits Limits
+	// PushLimits restricts rate and concurrency of requests sending data to database
+	PushLimits Limits
+	// CacheMaxBytes enables result cache of Range queries, limiting its estimated size in memory. Results are cached
+	// by query, step and options, and only missing tail of time range is fetched by next query. Start and end
+	// of cached queries are aligned to step.
+	CacheMaxBytes int64
+	// CacheTTL is time after which cached result is fetched again, DefaultCacheTTL is used if zero
+	CacheTTL time.Duration
+	// CacheTimestampOffset is period before now, which points are not cached in, since they can be not ingested yet.
+	// DefaultCacheTimestampOffset is used if zero.
+	CacheTimestampOffset time.Duration
+	// MaxResponseBytes limits size of response body of Instant, Query and Range queries, zero means unlimited.
+	// Decoding of response exceeding any of limits is aborted with LimitErr.
+	MaxResponseBytes int64
+	// MaxResponseSeries limits number of series returned by query, zero means unlimited
+	MaxResponseSeries int
+	// MaxResponsePoints limits total number of points of series returned by query, zero means unlimited
+	MaxResponsePoints int
+	// Compression defines how pushed metrics, imported series and remote write requests are compressed,
+	// and which compressed responses of queries are requested. Sizes of bodies are recorded by span attributes.
+	Compression Compression
+	// Retry defines how failed requests are retried, they are not retried by default
+	Retry RetryPolicy
+	// BatchSize enables background batching of samples added by Enqueue, batch is sent, when it has
+	// this number of samples. Zero disables batching, so Enqueue sends samples immediately.
+	BatchSize int
+	// BatchInterval is maximal time samples wait in batch, DefaultBatchInterval is used if zero
+	BatchInterval time.Duration
+	// BatchQueueSize limits number of samples waiting to be sent, 10 times BatchSize is used if it is smaller
+	BatchQueueSize int
+	// BatchOverflow defines what Enqueue does, when queue is full
+	BatchOverflow OverflowPolicy
+	// BatchErrorHandler is called with samples, that cannot be sent in background
+	BatchErrorHandler func(samples []Sample, err error)
+	// QueuePath is directory of durable queue, where requests of Push, RemoteWrite and batches are stored,
+	// when database is unavailable, to be sent in order, when Ping succeeds again.
+	// If queue is enabled, New does not fail, when database is unavailable.
+	QueuePath string
+	// QueueMaxBytes limits size of queue on disk, oldest requests are dropped, when it is exceeded. Zero means no limit.
+	QueueMaxBytes int64
+	// QueueReplayInterval is interval between attempts to send queued requests, DefaultQueueReplayInterval is used if zero
+	QueueReplayInterval time.Duration
 }
@@ -1,6 +1,7 @@
 package vmclient
 
 import (
+	"net/http"
 	"time"
 )
 
@@ -10,4 +11,61 @@ const DefaultStep = 5 * time.Minute
 
 const DefaultPushEndpoint = "/api/v1/import/prometheus"
 
+// DefaultImportEndpoint accepts data in JSON line format
+const DefaultImportEndpoint = "/api/v1/import"
+
+// DefaultRemoteWriteEndpoint accepts data in Prometheus remote write protocol
+const DefaultRemoteWriteEndpoint = "/api/v1/write"
+
 const DefaultEndpoint = "http://127.0.0.1:8428"
+
+// DefaultSplitConcurrency is number of chunks of split range query performed simultaneously
+const DefaultSplitConcurrency = 4
+
+// DefaultPostThreshold is length of url-encoded query arguments, above which queries are sent via POST
+const DefaultPostThreshold = 4096
+
+// DefaultBatchInterval is interval between flushes of samples enqueued
+const DefaultBatchInterval = 10 * time.Second
+
+// DefaultBatchFlushTimeout limits duration of sending single batch of samples enqueued
+const DefaultBatchFlushTimeout = 30 * time.Second
+
+// DefaultQueueReplayInterval is interval between attempts to send requests stored in disk queue
+const DefaultQueueReplayInterval = 5 * time.Second
+
+// DefaultRetryInitialBackoff is delay before second attempt of failed request
+const DefaultRetryInitialBackoff = 100 * time.Millisecond
+
+// DefaultRetryMaxBackoff limits delay between attempts of failed request
+const DefaultRetryMaxBackoff = 5 * time.Second
+
+// DefaultRetryableStatusCodes are response status codes, which requests are retried on
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultHealthCheckInterval is interval between checks of health of endpoints, read requests are balanced between
+const DefaultHealthCheckInterval = 5 * time.Second
+
+// DefaultCircuitMinRequests is minimal number of requests in window, which can open circuit breaker
+const DefaultCircuitMinRequests = 10
+
+// DefaultCircuitWindow is period, which requests are counted by circuit breaker in
+const DefaultCircuitWindow = 10 * time.Second
+
+// DefaultCircuitOpenTimeout is delay after circuit breaker is opened before database is probed
+const DefaultCircuitOpenTimeout = 5 * time.Second
+
+// DefaultCacheTTL is time after which result of range query cached by client is fetched again
+const DefaultCacheTTL = 10 * time.Minute
+
+// DefaultCacheTimestampOffset is period before now, which points are not cached in, like -search.cacheTimestampOffset
+// of Victoria Metrics
+const DefaultCacheTimestampOffset = 5 * time.Minute
+
+// DefaultTokenRefreshLeeway is period before expiration of OAuth2 token, when new one is requested
+const DefaultTokenRefreshLeeway = 30 * time.Second
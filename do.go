@@ -3,9 +3,11 @@ package vmclient
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -15,35 +17,67 @@ import (
 )
 
 type doParams struct {
-	query string
-	start time.Time
-	end   time.Time
-	when  time.Time
-	step  time.Duration
+	query  string
+	start  time.Time
+	end    time.Time
+	when   time.Time
+	step   time.Duration
+	match  []string
+	label  string
+	format string
+	opts   queryOptions
+
+	address string
 }
 
 func (c *Client) do(ctx context.Context, operation string, params doParams) (resp *http.Response, err error) {
+	switch operation {
+	case "ping":
+		return c.doAddress(ctx, operation, params)
+	case "instant", "range":
+		return limited(ctx, c.queryLimiter, func() (*http.Response, error) {
+			return c.doRead(ctx, operation, params)
+		})
+	default:
+		return c.doRead(ctx, operation, params)
+	}
+}
+
+// doRead performs read request, hedging it or balancing it between endpoints, if they are configured
+func (c *Client) doRead(ctx context.Context, operation string, params doParams) (resp *http.Response, err error) {
+	if c.hedgeDelay > 0 && (operation == "instant" || operation == "range") {
+		return c.doHedged(ctx, operation, params)
+	}
+	if c.pool != nil {
+		return c.doPool(ctx, operation, params, nil)
+	}
+	params.address = c.endpoint
+	return c.doAddress(ctx, operation, params)
+}
+
+// doAddress performs request on params.address
+func (c *Client) doAddress(ctx context.Context, operation string, params doParams) (resp *http.Response, err error) {
 	span := trace.SpanFromContext(ctx)
 	var endpoint string
 	var u *url.URL
+	var args url.Values
 	switch operation {
 	case "ping":
 		// https://github.com/VictoriaMetrics/VictoriaMetrics/issues/3539#issuecomment-1366469760
-		endpoint, err = url.JoinPath(c.endpoint, "-", "healthy")
+		endpoint, err = url.JoinPath(params.address, "-", "healthy")
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
 			return nil, err
 		}
 	case "instant":
-		u, err = url.Parse(c.endpoint)
+		u, err = c.selectURL(params.address, "api", "v1", "query")
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
-			return nil, fmt.Errorf("error parsing endpoint: %s", err)
+			return nil, err
 		}
-		u.Path += "prometheus/api/v1/query"
-		args := url.Values{}
+		args = url.Values{}
 		args.Set("query", params.query)
 		args.Set("time", strconv.FormatInt(params.when.Unix(), 10))
 		args.Set("step", params.step.String())
@@ -51,21 +85,21 @@ func (c *Client) do(ctx context.Context, operation string, params doParams) (res
 		if present {
 			args.Set("timeout", time.Until(deadline).String())
 		}
-		u.RawQuery = args.Encode()
+		params.opts.apply(args, span, c.redaction)
 		endpoint = u.String()
-		span.SetAttributes(semconv.DBQueryText(params.query),
+		span.SetAttributes(c.redaction.queryText(params.query)...)
+		span.SetAttributes(
 			attribute.String("end", params.when.Format(time.ANSIC)),
 			attribute.String("step", params.step.String()),
 		)
 	case "range":
-		u, err = url.Parse(c.endpoint)
+		u, err = c.selectURL(params.address, "api", "v1", "query_range")
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
-			return nil, fmt.Errorf("error parsing endpoint: %s", err)
+			return nil, err
 		}
-		u.Path += "prometheus/api/v1/query_range"
-		args := url.Values{}
+		args = url.Values{}
 		args.Set("query", params.query)
 		args.Set("start", strconv.FormatInt(params.start.Unix(), 10))
 		args.Set("end", strconv.FormatInt(params.end.Unix(), 10))
@@ -74,28 +108,137 @@ func (c *Client) do(ctx context.Context, operation string, params doParams) (res
 		if present {
 			args.Set("timeout", time.Until(deadline).String())
 		}
-		u.RawQuery = args.Encode()
+		params.opts.apply(args, span, c.redaction)
 		endpoint = u.String()
-		span.SetAttributes(semconv.DBQueryText(params.query),
+		span.SetAttributes(c.redaction.queryText(params.query)...)
+		span.SetAttributes(
 			attribute.String("start", params.start.Format(time.ANSIC)),
 			attribute.String("end", params.end.Format(time.ANSIC)),
 			attribute.String("step", params.step.String()),
 		)
+	case "series", "labels", "label_values", "export", "export_csv", "export_native":
+		switch operation {
+		case "series":
+			u, err = c.selectURL(params.address, "api", "v1", "series")
+		case "labels":
+			u, err = c.selectURL(params.address, "api", "v1", "labels")
+		case "label_values":
+			u, err = c.selectURL(params.address, "api", "v1", "label", params.label, "values")
+			span.SetAttributes(attribute.String("label", params.label))
+		case "export":
+			u, err = c.selectURL(params.address, "api", "v1", "export")
+		case "export_csv":
+			u, err = c.selectURL(params.address, "api", "v1", "export", "csv")
+		case "export_native":
+			u, err = c.selectURL(params.address, "api", "v1", "export", "native")
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		args = url.Values{}
+		for i := range params.match {
+			args.Add("match[]", params.match[i])
+		}
+		if !params.start.IsZero() {
+			args.Set("start", strconv.FormatInt(params.start.Unix(), 10))
+		}
+		if !params.end.IsZero() {
+			args.Set("end", strconv.FormatInt(params.end.Unix(), 10))
+		}
+		if params.format != "" {
+			args.Set("format", params.format)
+			span.SetAttributes(attribute.String("format", params.format))
+		}
+		endpoint = u.String()
+		span.SetAttributes(c.redaction.values("match", params.match)...)
+		span.SetAttributes(
+			attribute.String("start", params.start.Format(time.ANSIC)),
+			attribute.String("end", params.end.Format(time.ANSIC)),
+		)
 	default:
 		return nil, fmt.Errorf("unknown operation %s", operation)
 	}
-	span.SetAttributes(semconv.HTTPRequestMethodGet)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	method := http.MethodGet
+	var body io.Reader
+	if args != nil {
+		encoded := args.Encode()
+		if c.forcePost || (c.postThreshold > 0 && len(encoded) > c.postThreshold) {
+			// long queries can exceed URL length limits of proxies, so they are sent in request body
+			method = http.MethodPost
+			body = strings.NewReader(encoded)
+		} else {
+			endpoint += "?" + encoded
+		}
+	}
+	if c.cluster {
+		span.SetAttributes(attribute.String("tenant", c.tenant.String()))
+	}
+	span.SetAttributes(semconv.HTTPRequestMethodKey.String(method))
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	c.redaction.setHeaders(span, c.headers)
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	accept := c.compression.acceptEncoding()
+	if accept != "" {
+		req.Header.Set("Accept-Encoding", accept)
+	}
+	var res *http.Response
+	if operation == "ping" {
+		// ping is not guarded, since it is used for probing database by circuit breaker
+		res, err = c.send(operation, req)
+	} else {
+		res, err = c.sendGuarded(operation, req)
+	}
+	if err == nil {
+		c.telemetry.measureResponse(ctx, operation, req, res)
+		err = decompressResponse(res, span)
+		if err != nil {
+			res.Body.Close()
+		}
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+	return res, nil
+}
+
+// write sends body to ingestion API of Victoria Metrics
+func (c *Client) write(ctx context.Context, operation string, u *url.URL, body io.Reader, header http.Header) (resp *http.Response, err error) {
+	span := trace.SpanFromContext(ctx)
+	if c.cluster {
+		span.SetAttributes(attribute.String("tenant", c.tenant.String()))
+	}
+	span.SetAttributes(semconv.HTTPRequestMethodPost)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 		return nil, err
 	}
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	c.redaction.setHeaders(span, c.headers)
 	for k, v := range c.headers {
-		span.SetAttributes(semconv.HTTPRequestHeader(k, v))
 		req.Header.Set(k, v)
 	}
-	res, err := c.hclient.Do(req)
+	res, err := limited(ctx, c.pushLimiter, func() (*http.Response, error) {
+		return c.sendGuarded(operation, req)
+	})
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
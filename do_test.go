@@ -0,0 +1,82 @@
+package vmclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostQueriesAgainstHttpMock(tt *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+"/prometheus/api/v1/query",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "wrong content type"), nil
+			}
+			err := req.ParseForm()
+			if err != nil {
+				return nil, err
+			}
+			if req.PostForm.Get("query") == "" || req.URL.RawQuery != "" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "query is not in body"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+		})
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/query",
+		httpmock.NewStringResponder(http.StatusOK,
+			`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"2"]}}`))
+
+	tt.Run("short query via get", func(t *testing.T) {
+		client, err := New(t.Context(), Config{
+			Address:    DefaultEndpoint,
+			HttpClient: &http.Client{Transport: mockTransport},
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		result, err := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		if assert.NoError(t, err) {
+			assert.Equal(t, float64(2), result.Scalar.Value)
+		}
+	})
+
+	tt.Run("long query via post", func(t *testing.T) {
+		client, err := New(t.Context(), Config{
+			Address:    DefaultEndpoint,
+			HttpClient: &http.Client{Transport: mockTransport},
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		query := strings.Repeat(`up{job="something"} or `, 500) + "time()"
+		result, err := client.Query(t.Context(), query, time.Now(), DefaultStep)
+		if assert.NoError(t, err) {
+			assert.Equal(t, float64(1), result.Scalar.Value)
+		}
+	})
+
+	tt.Run("forced post", func(t *testing.T) {
+		client, err := New(t.Context(), Config{
+			Address:    DefaultEndpoint,
+			HttpClient: &http.Client{Transport: mockTransport},
+			ForcePost:  true,
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		result, err := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		if assert.NoError(t, err) {
+			assert.Equal(t, float64(1), result.Scalar.Value)
+		}
+	})
+}
@@ -16,6 +16,18 @@ var (
 	ErrUnexpectedResponse = errors.New("unexpected response")
 	// ErrQueryError happens, when Victoria Metrics cannot process query
 	ErrQueryError = errors.New("query error")
+	// ErrUnexpectedResultType happens, when query returns data of type, that cannot be processed by method called
+	ErrUnexpectedResultType = errors.New("unexpected result type")
+	// ErrNoEndpoint happens, when address required for operation is not configured
+	ErrNoEndpoint = errors.New("endpoint is not configured")
+	// ErrQueueFull happens, when samples are dropped, because batching queue is full
+	ErrQueueFull = errors.New("queue is full")
+	// ErrClosed happens, when samples are enqueued into closed client
+	ErrClosed = errors.New("client is closed")
+	// ErrCircuitOpen happens, when request fails fast, because circuit breaker is open
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrLimitExceeded happens, when query response exceeds limits of Config, see LimitErr
+	ErrLimitExceeded = errors.New("response limit exceeded")
 )
 
 // Err is custom error
@@ -52,7 +64,8 @@ type errorResponse struct {
 }
 
 func handleErrorResponse(resp *http.Response, span trace.Span) error {
-	if resp.StatusCode == http.StatusOK {
+	// ingestion API responds with 204 No Content
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
 		span.AddEvent("response code is correct")
 		return nil
 	}
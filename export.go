@@ -0,0 +1,191 @@
+package vmclient
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// jsonFloat is float value of JSON line, which can be number or string like "NaN" or "Inf"
+type jsonFloat float64
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if len(raw) > 1 && raw[0] == '"' {
+		unquoted, err := strconv.Unquote(raw)
+		if err != nil {
+			return err
+		}
+		raw = unquoted
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing value %s: %w", raw, err)
+	}
+	*f = jsonFloat(parsed)
+	return nil
+}
+
+type exportLine struct {
+	Metric     map[string]string `json:"metric"`
+	Values     []jsonFloat       `json:"values"`
+	Timestamps []int64           `json:"timestamps"`
+}
+
+func (m *exportLine) convert() (output Range, err error) {
+	if len(m.Values) != len(m.Timestamps) {
+		return output, fmt.Errorf("%v values are exported for %v timestamps", len(m.Values), len(m.Timestamps))
+	}
+	output.Labels = m.Metric
+	output.Values = make([]Result, len(m.Values))
+	for i := range m.Values {
+		output.Values[i] = Result{Value: float64(m.Values[i]), Timestamp: time.UnixMilli(m.Timestamps[i])}
+	}
+	return output, nil
+}
+
+// Export streams raw samples of time series matching any of match[] selectors on time range between start and end,
+// as described here https://docs.victoriametrics.com/victoriametrics/single-server-victoriametrics/#how-to-export-data-in-json-line-format
+// Series are decoded one by one while response body is being read. Iteration stops on first error.
+func (c *Client) Export(initialCtx context.Context, match []string, start, end time.Time) iter.Seq2[Range, error] {
+	return func(yield func(Range, error) bool) {
+		ctx, span := c.startExportSpan(initialCtx, "export")
+		defer span.End()
+		var err error
+		defer c.telemetry.record(ctx, "export", c.endpoint, time.Now(), &err)
+
+		body, err := c.export(ctx, span, "export", doParams{match: match, start: start, end: end})
+		if err != nil {
+			yield(Range{}, err)
+			return
+		}
+		defer body.Close()
+		decoder := json.NewDecoder(body)
+		var series int
+		for {
+			var line exportLine
+			err = decoder.Decode(&line)
+			if errors.Is(err, io.EOF) {
+				err = nil
+				break
+			}
+			var output Range
+			if err == nil {
+				output, err = line.convert()
+			}
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				span.RecordError(err)
+				yield(Range{}, err)
+				return
+			}
+			series++
+			if !yield(output, nil) {
+				span.SetAttributes(attribute.Int("series", series))
+				c.telemetry.returned(ctx, "export", c.endpoint, series)
+				span.SetStatus(codes.Ok, "export interrupted")
+				return
+			}
+		}
+		span.SetAttributes(attribute.Int("series", series))
+		c.telemetry.returned(ctx, "export", c.endpoint, series)
+		span.SetStatus(codes.Ok, "data received")
+	}
+}
+
+// ExportCSV streams raw samples of time series matching any of match[] selectors on time range between start and end
+// in CSV format, as described here https://docs.victoriametrics.com/victoriametrics/single-server-victoriametrics/#how-to-export-csv-data
+// Format is comma-separated list of columns, like `__name__,__value__,__timestamp__:unix_s,job`.
+// Callback is called for every record, and export is stopped, when it returns error.
+// Every record is new slice, so callback can keep it, like for batching records into object storage.
+func (c *Client) ExportCSV(initialCtx context.Context, match []string, format string, start, end time.Time,
+	callback func(record []string) error) (err error) {
+	ctx, span := c.startExportSpan(initialCtx, "export_csv")
+	defer span.End()
+	defer c.telemetry.record(ctx, "export_csv", c.endpoint, time.Now(), &err)
+
+	body, err := c.export(ctx, span, "export_csv", doParams{match: match, format: format, start: start, end: end})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	reader := csv.NewReader(body)
+	var records int
+	for {
+		record, errRead := reader.Read()
+		if errors.Is(errRead, io.EOF) {
+			break
+		}
+		if errRead == nil {
+			errRead = callback(record)
+		}
+		if errRead != nil {
+			span.SetStatus(codes.Error, errRead.Error())
+			span.RecordError(errRead)
+			return errRead
+		}
+		records++
+	}
+	span.SetAttributes(attribute.Int("records", records))
+	c.telemetry.returned(ctx, "export_csv", c.endpoint, records)
+	span.SetStatus(codes.Ok, "data received")
+	return nil
+}
+
+// ExportNative copies raw samples of time series matching any of match[] selectors on time range between start
+// and end in native binary format into w, as described here
+// https://docs.victoriametrics.com/victoriametrics/single-server-victoriametrics/#how-to-export-data-in-native-format
+// Exported data can be imported back into Victoria Metrics via /api/v1/import/native.
+func (c *Client) ExportNative(initialCtx context.Context, match []string, start, end time.Time, w io.Writer) (written int64, err error) {
+	ctx, span := c.startExportSpan(initialCtx, "export_native")
+	defer span.End()
+	defer c.telemetry.record(ctx, "export_native", c.endpoint, time.Now(), &err)
+
+	body, err := c.export(ctx, span, "export_native", doParams{match: match, start: start, end: end})
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	written, err = io.Copy(w, body)
+	span.SetAttributes(attribute.Int64("bytes", written))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return written, err
+	}
+	span.SetStatus(codes.Ok, "data received")
+	return written, nil
+}
+
+func (c *Client) startExportSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+}
+
+func (c *Client) export(ctx context.Context, span trace.Span, operation string, params doParams) (body io.ReadCloser, err error) {
+	resp, err := c.do(ctx, operation, params)
+	if err != nil {
+		return nil, err
+	}
+	err = handleErrorResponse(resp, span)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	span.AddEvent("request performed")
+	return resp.Body, nil
+}
@@ -0,0 +1,88 @@
+package vmclient
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportAgainstHttpMock(tt *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/export",
+		httpmock.NewStringResponder(http.StatusOK,
+			`{"metric":{"__name__":"a","job":"vmclient"},"values":[1,2.5],"timestamps":[1000,2000]}`+"\n"+
+				`{"metric":{"__name__":"b","job":"vmclient"},"values":["NaN"],"timestamps":[3000]}`+"\n"))
+	mockTransport.RegisterMatcherResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/export/csv",
+		httpmock.NewMatcher("validateFormat", func(req *http.Request) bool {
+			return req.URL.Query().Get("format") == "__name__,__value__,__timestamp__:unix_s"
+		}),
+		httpmock.NewStringResponder(http.StatusOK, "a,1,1\na,2.5,2\nb,3,3\n"))
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/export/native",
+		httpmock.NewBytesResponder(http.StatusOK, []byte{0x01, 0x02, 0x03}))
+
+	client, err := New(tt.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		tt.Errorf("error creating client: %s", err)
+		return
+	}
+	match := []string{`{job="vmclient"}`}
+
+	tt.Run("json lines", func(t *testing.T) {
+		var exported []Range
+		for series, errE := range client.Export(t.Context(), match, time.Time{}, time.Time{}) {
+			if !assert.NoError(t, errE) {
+				return
+			}
+			exported = append(exported, series)
+		}
+		assert.Len(t, exported, 2)
+		assert.Equal(t, "a", exported[0].Name())
+		assert.Equal(t, []Result{
+			{Value: 1, Timestamp: time.UnixMilli(1000)},
+			{Value: 2.5, Timestamp: time.UnixMilli(2000)},
+		}, exported[0].Values)
+		assert.True(t, math.IsNaN(exported[1].Values[0].Value))
+	})
+
+	tt.Run("json lines interrupted", func(t *testing.T) {
+		var exported int
+		for range client.Export(t.Context(), match, time.Time{}, time.Time{}) {
+			exported++
+			break
+		}
+		assert.Equal(t, 1, exported)
+	})
+
+	tt.Run("csv", func(t *testing.T) {
+		var names []string
+		var records [][]string
+		errE := client.ExportCSV(t.Context(), match, "__name__,__value__,__timestamp__:unix_s", time.Time{}, time.Time{},
+			func(record []string) error {
+				names = append(names, record[0])
+				records = append(records, record)
+				return nil
+			})
+		assert.NoError(t, errE)
+		assert.Equal(t, []string{"a", "a", "b"}, names)
+		assert.Equal(t, [][]string{{"a", "1", "1"}, {"a", "2.5", "2"}, {"b", "3", "3"}}, records,
+			"records kept by callback are overwritten")
+	})
+
+	tt.Run("native", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		written, errE := client.ExportNative(t.Context(), match, time.Time{}, time.Time{}, &buf)
+		assert.NoError(t, errE)
+		assert.Equal(t, int64(3), written)
+		assert.Equal(t, []byte{0x01, 0x02, 0x03}, buf.Bytes())
+	})
+}
@@ -0,0 +1,101 @@
+package vmclient
+
+import (
+	"fmt"
+	"io"
+)
+
+// LimitErr happens, when response of query exceeds MaxResponseBytes, MaxResponseSeries or MaxResponsePoints
+// of Config. It carries numbers of bytes, series and points read before decoding was aborted.
+type LimitErr struct {
+	// Limit is name of limit exceeded - "bytes", "series" or "points"
+	Limit string
+	// Max is value of limit exceeded
+	Max    int64
+	Bytes  int64
+	Series int
+	Points int
+}
+
+func (e LimitErr) Error() string {
+	return fmt.Sprintf("%s limit %v is exceeded after reading %v bytes, %v series and %v points",
+		e.Limit, e.Max, e.Bytes, e.Series, e.Points)
+}
+
+func (e LimitErr) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// responseLimits restricts size of query response
+type responseLimits struct {
+	maxBytes  int64
+	maxSeries int
+	maxPoints int
+}
+
+func (l responseLimits) enabled() bool {
+	return l.maxBytes > 0 || l.maxSeries > 0 || l.maxPoints > 0
+}
+
+// responseCounter counts bytes, series and points of response, failing, when limits are exceeded
+type responseCounter struct {
+	limits responseLimits
+	bytes  int64
+	series int
+	points int
+}
+
+func (c *responseCounter) exceeded(limit string, max int64) LimitErr {
+	return LimitErr{Limit: limit, Max: max, Bytes: c.bytes, Series: c.series, Points: c.points}
+}
+
+// add counts series with number of points given
+func (c *responseCounter) add(points int) error {
+	c.series++
+	c.points += points
+	if c.limits.maxSeries > 0 && c.series > c.limits.maxSeries {
+		return c.exceeded("series", int64(c.limits.maxSeries))
+	}
+	if c.limits.maxPoints > 0 && c.points > c.limits.maxPoints {
+		return c.exceeded("points", int64(c.limits.maxPoints))
+	}
+	return nil
+}
+
+// reader returns body, which reading fails, when it exceeds bytes limit
+func (c *responseCounter) reader(body io.Reader) io.Reader {
+	if c.limits.maxBytes <= 0 {
+		return body
+	}
+	return countingReader{body: body, counter: c}
+}
+
+type countingReader struct {
+	body    io.Reader
+	counter *responseCounter
+}
+
+func (r countingReader) Read(p []byte) (n int, err error) {
+	// no more than one byte exceeding limit is read, so decoder cannot complete value beyond limit
+	remaining := r.counter.limits.maxBytes - r.counter.bytes + 1
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err = r.body.Read(p)
+	r.counter.bytes += int64(n)
+	if r.counter.bytes > r.counter.limits.maxBytes {
+		return n, r.counter.exceeded("bytes", r.counter.limits.maxBytes)
+	}
+	return n, err
+}
+
+// countRanges counts series and points of data, which is already decoded
+func (c *responseCounter) countRanges(data []Range) error {
+	for i := range data {
+		err := c.add(len(data[i].Values))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package vmclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseLimitsAgainstHttpMock(t *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query_range`,
+		httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1734677495,"1"],[1734677555,"1"]]},
+			{"metric":{"job":"b"},"values":[[1734677495,"1"],[1734677555,"1"]]},
+			{"metric":{"job":"c"},"values":[[1734677495,"1"],[1734677555,"1"]]}]}}`))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query\?`,
+		httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"job":"a"},"value":[1734677495,"1"]},
+			{"metric":{"job":"b"},"value":[1734677495,"1"]}]}}`))
+
+	for name, testCase := range map[string]struct {
+		cfg      Config
+		expected LimitErr
+	}{
+		"series": {
+			cfg:      Config{MaxResponseSeries: 2},
+			expected: LimitErr{Limit: "series", Max: 2, Series: 3, Points: 6},
+		},
+		"points": {
+			cfg:      Config{MaxResponsePoints: 3},
+			expected: LimitErr{Limit: "points", Max: 3, Series: 2, Points: 4},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testCase.cfg.Address = DefaultEndpoint
+			testCase.cfg.HttpClient = &http.Client{Transport: mockTransport}
+			client, err := New(t.Context(), testCase.cfg)
+			if err != nil {
+				t.Errorf("error creating client: %s", err)
+				return
+			}
+			_, err = client.Range(t.Context(), "up", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+			assert.ErrorIs(t, err, ErrLimitExceeded)
+			var limitErr LimitErr
+			if assert.ErrorAs(t, err, &limitErr) {
+				assert.Equal(t, testCase.expected, limitErr)
+			}
+		})
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		client, err := New(t.Context(), Config{
+			Address:          DefaultEndpoint,
+			HttpClient:       &http.Client{Transport: mockTransport},
+			MaxResponseBytes: 100,
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		_, err = client.Query(t.Context(), "up", time.Now(), DefaultStep)
+		var limitErr LimitErr
+		if assert.ErrorAs(t, err, &limitErr) {
+			assert.Equal(t, "bytes", limitErr.Limit)
+			assert.Greater(t, limitErr.Bytes, int64(100))
+		}
+		// series decoded before limit is exceeded are yielded
+		var lastErr error
+		for _, errS := range client.RangeStream(t.Context(), "up", time.Now().Add(-time.Hour), time.Now(), time.Minute) {
+			lastErr = errS
+		}
+		assert.ErrorIs(t, lastErr, ErrLimitExceeded)
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		client, err := New(t.Context(), Config{
+			Address:           DefaultEndpoint,
+			HttpClient:        &http.Client{Transport: mockTransport},
+			MaxResponseSeries: 1,
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		_, err = client.Instant(t.Context(), "up", time.Now(), DefaultStep)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+
+	t.Run("vector decoding is aborted", func(t *testing.T) {
+		// series after exceeded limit are never decoded, so broken tail of response is not reached
+		truncated := httpmock.NewMockTransport()
+		truncated.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+			httpmock.NewStringResponder(http.StatusOK, "OK"))
+		truncated.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query\?`,
+			httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"job":"a"},"value":[1734677495,"1"]},
+				{"metric":{"job":"b"},"value":[1734677495,"1"]},
+				{"metric":{"job":"c"},"valu`))
+		client, err := New(t.Context(), Config{
+			Address:           DefaultEndpoint,
+			HttpClient:        &http.Client{Transport: truncated},
+			MaxResponseSeries: 1,
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		_, err = client.Query(t.Context(), "up", time.Now(), DefaultStep)
+		var limitErr LimitErr
+		if assert.ErrorAs(t, err, &limitErr) {
+			assert.Equal(t, LimitErr{Limit: "series", Max: 1, Series: 2, Points: 2}, limitErr)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package vmclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// hedgedResult is outcome of one of duplicated requests
+type hedgedResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	hedge  bool
+}
+
+func (r hedgedResult) succeeded() bool {
+	return r.err == nil && r.resp.StatusCode >= http.StatusOK && r.resp.StatusCode < http.StatusMultipleChoices
+}
+
+// discard releases response of request, which result is not used
+func (r hedgedResult) discard() {
+	if r.resp != nil {
+		io.Copy(io.Discard, r.resp.Body)
+		r.resp.Body.Close()
+	}
+	r.cancel()
+}
+
+// cancelOnClose cancels context of request, when its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// doHedged performs read request and, if it is not answered within hedge delay, sends its duplicate to
+// other endpoint of pool or by other connection. First successful response is returned, other request is canceled.
+func (c *Client) doHedged(ctx context.Context, operation string, params doParams) (resp *http.Response, err error) {
+	span := trace.SpanFromContext(ctx)
+	results := make(chan hedgedResult, 2)
+	var cancels []context.CancelFunc
+	// release frees slot of limiter taken by request, when its result is discarded or its body is closed
+	launch := func(ep *poolEndpoint, hedge bool, release func()) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		// every attempt has its own span, so errors of attempt canceled by other one are not recorded
+		// on span of operation
+		attemptCtx, attemptSpan := c.tracer.Start(attemptCtx, "attempt",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attribute.Bool("hedge", hedge)),
+		)
+		go func() {
+			defer attemptSpan.End()
+			result := hedgedResult{cancel: func() {
+				cancel()
+				release()
+			}, hedge: hedge}
+			if c.pool != nil {
+				result.resp, result.err = c.doPool(attemptCtx, operation, params, ep)
+			} else {
+				attemptParams := params
+				attemptParams.address = c.endpoint
+				result.resp, result.err = c.doAddress(attemptCtx, operation, attemptParams)
+			}
+			results <- result
+		}()
+	}
+
+	var primary *poolEndpoint
+	if c.pool != nil {
+		primary = c.pool.pick(nil)
+	}
+	// slot of primary request is taken by caller
+	launch(primary, false, func() {})
+	pending := 1
+	hedged := false
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+	var failed *hedgedResult
+	for {
+		select {
+		case <-timer.C:
+			// duplicate is sent only if limiter has free slot for it, so hedging does not exceed limits
+			release, allowed := c.queryLimiter.tryAcquire()
+			if !allowed {
+				span.AddEvent("hedged request skipped")
+				continue
+			}
+			var secondary *poolEndpoint
+			if c.pool != nil {
+				secondary = c.pool.pick(map[*poolEndpoint]bool{primary: true})
+			}
+			attrs := []attribute.KeyValue{attribute.String("delay", c.hedgeDelay.String())}
+			if secondary != nil {
+				attrs = append(attrs, attribute.String("endpoint", secondary.address))
+			}
+			span.AddEvent("hedged request sent", trace.WithAttributes(attrs...))
+			span.SetAttributes(attribute.Bool("hedged", true))
+			hedged = true
+			pending++
+			launch(secondary, true, release)
+		case result := <-results:
+			pending--
+			if result.succeeded() {
+				if hedged {
+					span.AddEvent("hedged request finished", trace.WithAttributes(
+						attribute.Bool("hedge_won", result.hedge),
+					))
+				}
+				// first of cancels belongs to primary request, second one - to hedged one
+				for i, cancel := range cancels {
+					if (i == 1) != result.hedge {
+						cancel()
+					}
+				}
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						(<-results).discard()
+					}
+				}(pending)
+				result.resp.Body = cancelOnClose{ReadCloser: result.resp.Body, cancel: result.cancel}
+				return result.resp, nil
+			}
+			if failed != nil {
+				failed.discard()
+			}
+			failed = &result
+			if pending > 0 {
+				continue
+			}
+			if !hedged {
+				timer.Stop()
+			}
+			if failed.resp != nil {
+				failed.resp.Body = cancelOnClose{ReadCloser: failed.resp.Body, cancel: failed.cancel}
+			} else {
+				failed.cancel()
+			}
+			return failed.resp, failed.err
+		}
+	}
+}
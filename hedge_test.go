@@ -0,0 +1,120 @@
+package vmclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHedgeAgainstHttpMock(t *testing.T) {
+	const slow, fast = "http://slow:8428", "http://fast:8428"
+	var canceled, answered atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	for _, address := range []string{slow, fast} {
+		mockTransport.RegisterResponder(http.MethodGet, address+"/-/healthy",
+			httpmock.NewStringResponder(http.StatusOK, "OK"))
+		mockTransport.RegisterResponder(http.MethodGet, `=~^`+address+`/prometheus/api/v1/query`,
+			func(req *http.Request) (*http.Response, error) {
+				if address == slow {
+					select {
+					case <-req.Context().Done():
+						canceled.Add(1)
+						return nil, req.Context().Err()
+					case <-time.After(time.Second):
+					}
+				}
+				answered.Add(1)
+				return httpmock.NewStringResponse(http.StatusOK,
+					`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+			})
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	client, err := New(t.Context(), Config{
+		Addresses:           []string{slow, fast},
+		HttpClient:          &http.Client{Transport: mockTransport},
+		HealthCheckInterval: time.Hour,
+		HedgeDelay:          10 * time.Millisecond,
+		TracerProvider:      sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	defer client.Close(t.Context())
+
+	started := time.Now()
+	result, err := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	if assert.NoError(t, err) {
+		assert.Equal(t, float64(1), result.Scalar.Value)
+	}
+	assert.Less(t, time.Since(started), 500*time.Millisecond, "slow request is awaited")
+	assert.Eventually(t, func() bool { return canceled.Load() == 1 }, time.Second, 10*time.Millisecond,
+		"slow request is not canceled")
+	assert.Equal(t, int32(1), answered.Load())
+
+	var attempts int
+	assert.Eventually(t, func() bool {
+		attempts = 0
+		for _, span := range recorder.Ended() {
+			if span.Name() == "attempt" {
+				attempts++
+			}
+		}
+		return attempts == 2
+	}, time.Second, 10*time.Millisecond, "attempts do not have own spans")
+	for _, span := range recorder.Ended() {
+		if span.Name() != "instant" {
+			continue
+		}
+		assert.NotEqual(t, codes.Error, span.Status().Code, "canceled attempt fails operation")
+		for _, event := range span.Events() {
+			assert.NotEqual(t, "exception", event.Name, "error of canceled attempt is recorded")
+		}
+	}
+}
+
+func TestHedgeRespectsQueryLimits(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, `=~/-/healthy$`, httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~/prometheus/api/v1/query`,
+		func(req *http.Request) (*http.Response, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				observed := maxInFlight.Load()
+				if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Addresses:           []string{"http://first:8428", "http://second:8428"},
+		HttpClient:          &http.Client{Transport: mockTransport},
+		HealthCheckInterval: time.Hour,
+		HedgeDelay:          10 * time.Millisecond,
+		QueryLimits:         Limits{MaxInFlight: 1},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	defer client.Close(t.Context())
+
+	_, err = client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), maxInFlight.Load(), "hedged request exceeds limit of requests in flight")
+	assert.Equal(t, 1, mockTransport.GetTotalCallCount()-2, "hedged request is sent without free slot")
+}
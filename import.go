@@ -0,0 +1,145 @@
+package vmclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PushSeries sends time series with explicit timestamps using Import
+func (c *Client) PushSeries(ctx context.Context, series ...Range) error {
+	return c.Import(ctx, slices.Values(series))
+}
+
+// Import sends time series with explicit timestamps in JSON line format, as described here
+// https://docs.victoriametrics.com/victoriametrics/single-server-victoriametrics/#how-to-import-data-in-json-line-format
+// Series are encoded while request body is being sent, so they are not required to fit in memory all together.
+func (c *Client) Import(initialCtx context.Context, series iter.Seq[Range]) (err error) {
+	ctx, span := c.tracer.Start(initialCtx, "import",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBClientConnectionPoolName(c.insertEndpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics"),
+			attribute.String("extra_labels", c.extraLabels),
+		),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "import", c.insertEndpoint, time.Now(), &err)
+
+	u, err := c.insertURL(DefaultImportEndpoint)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+	c.setExtraLabels(u)
+
+	encoding := c.compression.bodyEncoding("")
+	pr, pw := io.Pipe()
+	compressed := &countingWriter{w: pw}
+	zw, err := compressingWriter(encoding, compressed)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+	uncompressed := &countingWriter{w: zw}
+	var lines, samples int
+	encoded := make(chan struct{})
+	go func() {
+		defer close(encoded)
+		var errEncoding error
+		lines, samples, errEncoding = encodeJSONLines(uncompressed, series)
+		if errEncoding == nil {
+			errEncoding = zw.Close()
+		}
+		pw.CloseWithError(errEncoding)
+	}()
+	header := http.Header{}
+	header.Set("Content-Type", "application/stream+json")
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	resp, err := c.write(ctx, "import", u, pr, header)
+	// encoder is unblocked, if request is finished before whole body is read
+	pr.Close()
+	<-encoded
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("series", lines), attribute.Int("samples", samples),
+		attribute.Int64("body.uncompressed", uncompressed.n),
+		attribute.Int64("body.compressed", compressed.n),
+	)
+	err = handleErrorResponse(resp, span)
+	if err != nil {
+		return err
+	}
+	span.SetStatus(codes.Ok, "series are imported")
+	return nil
+}
+
+// encodeJSONLines writes series as lines like {"metric":{...},"values":[...],"timestamps":[...]}
+func encodeJSONLines(w io.Writer, series iter.Seq[Range]) (lines, samples int, err error) {
+	bw := bufio.NewWriter(w)
+	var metric, line []byte
+	for s := range series {
+		if len(s.Values) == 0 {
+			continue
+		}
+		metric, err = json.Marshal(s.Labels)
+		if err != nil {
+			return lines, samples, err
+		}
+		line = append(line[:0], `{"metric":`...)
+		line = append(line, metric...)
+		line = append(line, `,"values":[`...)
+		for i := range s.Values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = appendJSONFloat(line, s.Values[i].Value)
+		}
+		line = append(line, `],"timestamps":[`...)
+		for i := range s.Values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = strconv.AppendInt(line, s.Values[i].Timestamp.UnixMilli(), 10)
+		}
+		line = append(line, "]}\n"...)
+		_, err = bw.Write(line)
+		if err != nil {
+			return lines, samples, err
+		}
+		lines++
+		samples += len(s.Values)
+	}
+	return lines, samples, bw.Flush()
+}
+
+// appendJSONFloat appends value, using strings for NaN and infinities, since JSON numbers cannot represent them
+func appendJSONFloat(dst []byte, value float64) []byte {
+	switch {
+	case math.IsNaN(value):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(value, 1):
+		return append(dst, `"Inf"`...)
+	case math.IsInf(value, -1):
+		return append(dst, `"-Inf"`...)
+	}
+	return strconv.AppendFloat(dst, value, 'g', -1, 64)
+}
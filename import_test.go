@@ -0,0 +1,65 @@
+package vmclient
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeJSONLines(t *testing.T) {
+	buf := bytes.Buffer{}
+	lines, samples, err := encodeJSONLines(&buf, func(yield func(Range) bool) {
+		yield(Range{
+			Labels: map[string]string{LabelForName: "something", "job": "vmclient"},
+			Values: []Result{
+				{Value: 1.5, Timestamp: time.UnixMilli(1734677495161)},
+				{Value: math.NaN(), Timestamp: time.UnixMilli(1734677555161)},
+			},
+		})
+		yield(Range{Labels: map[string]string{LabelForName: "empty"}})
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, lines)
+		assert.Equal(t, 2, samples)
+		assert.Equal(t, `{"metric":{"__name__":"something","job":"vmclient"},"values":[1.5,"NaN"],"timestamps":[1734677495161,1734677555161]}`+"\n",
+			buf.String())
+	}
+}
+
+func TestImportAgainstHttpMock(t *testing.T) {
+	var received string
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultImportEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			received = string(body)
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	err = client.PushSeries(t.Context(),
+		Range{Labels: map[string]string{LabelForName: "a"}, Values: []Result{{Value: 1, Timestamp: time.UnixMilli(1000)}}},
+		Range{Labels: map[string]string{LabelForName: "b"}, Values: []Result{{Value: 2, Timestamp: time.UnixMilli(2000)}}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"metric":{"__name__":"a"},"values":[1],"timestamps":[1000]}`+"\n"+
+		`{"metric":{"__name__":"b"},"values":[2],"timestamps":[2000]}`+"\n", received)
+}
@@ -7,7 +7,7 @@ import (
 	"strconv"
 	"time"
 
-	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
 	"go.opentelemetry.io/otel/trace"
@@ -52,50 +52,189 @@ type instantRawResponse struct {
 	Data   instantRespData `json:"data"`
 }
 
+type queryRespData struct {
+	ResultType ResultType      `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+func (m *queryRespData) convert() (output QueryResult, err error) {
+	if m.ResultType == "" {
+		// result type is not provided, old behaviour expecting vector is used
+		m.ResultType = ResultTypeVector
+	}
+	output.Type = m.ResultType
+	switch m.ResultType {
+	case ResultTypeVector:
+		var raw []instantResult
+		err = json.Unmarshal(m.Result, &raw)
+		if err != nil {
+			return output, err
+		}
+		output.Vector = make([]Instant, len(raw))
+		for i := range raw {
+			output.Vector[i], err = raw[i].convert()
+			if err != nil {
+				return output, err
+			}
+		}
+	case ResultTypeMatrix:
+		var raw []rangeResult
+		err = json.Unmarshal(m.Result, &raw)
+		if err != nil {
+			return output, err
+		}
+		output.Matrix = make([]Range, len(raw))
+		for i := range raw {
+			output.Matrix[i], err = raw[i].convert()
+			if err != nil {
+				return output, err
+			}
+		}
+	case ResultTypeScalar:
+		var raw []any
+		err = json.Unmarshal(m.Result, &raw)
+		if err != nil {
+			return output, err
+		}
+		output.Scalar, err = parseRangeValue(raw)
+		if err != nil {
+			return output, err
+		}
+	case ResultTypeString:
+		var raw []any
+		err = json.Unmarshal(m.Result, &raw)
+		if err != nil {
+			return output, err
+		}
+		if len(raw) != 2 {
+			return output, fmt.Errorf("exactly two parameters are expected, instead of %v", raw)
+		}
+		rawTimeStamp, ok := raw[0].(float64)
+		if !ok {
+			return output, fmt.Errorf("error parsing %s as float64", raw[0])
+		}
+		output.String.Timestamp = time.UnixMilli(int64(1000 * rawTimeStamp))
+		output.String.Value, ok = raw[1].(string)
+		if !ok {
+			return output, fmt.Errorf("error typecasting %v to string", raw[1])
+		}
+	default:
+		return output, fmt.Errorf("%w: %s", ErrUnexpectedResultType, m.ResultType)
+	}
+	return output, nil
+}
+
 // Instant makes instant query described here
 // https://docs.victoriametrics.com/victoriametrics/keyconcepts/#instant-query
-func (c *Client) Instant(initialCtx context.Context, query string, when time.Time, step time.Duration) (data []Instant, err error) {
-	ctx, span := otel.GetTracerProvider().Tracer("vmclient").Start(initialCtx, "instant",
+// Scalar result is returned as single Instant without labels, use Query for string and matrix results.
+func (c *Client) Instant(initialCtx context.Context, query string, when time.Time, step time.Duration, opts ...QueryOption) (data []Instant, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "instant",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
 			semconv.DBSystemNameKey.String("Victoria Metrics")),
 	)
 	defer span.End()
+	defer c.telemetry.record(ctx, "instant", c.endpoint, time.Now(), &err)
 
-	var output Instant
-	resp, err := c.do(ctx, "instant", doParams{query: query, when: when, step: step})
+	result, err := c.query(ctx, span, doParams{query: query, when: when, step: step, opts: newQueryOptions(opts)})
 	if err != nil {
 		return nil, err
 	}
+	switch result.Type {
+	case ResultTypeVector:
+		data = result.Vector
+	case ResultTypeScalar:
+		data = []Instant{{Result: result.Scalar, Labels: map[string]string{}}}
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnexpectedResultType, result.Type)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	c.telemetry.returned(ctx, "instant", c.endpoint, len(data))
+	span.SetStatus(codes.Ok, "data received")
+	return data, nil
+}
+
+// Query makes instant query like Instant does, but returns result of any type - vector, matrix, scalar or string
+func (c *Client) Query(initialCtx context.Context, query string, when time.Time, step time.Duration, opts ...QueryOption) (data QueryResult, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "instant",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "instant", c.endpoint, time.Now(), &err)
+
+	data, err = c.query(ctx, span, doParams{query: query, when: when, step: step, opts: newQueryOptions(opts)})
+	if err != nil {
+		return data, err
+	}
+	c.telemetry.returned(ctx, "instant", c.endpoint, len(data.Vector)+len(data.Matrix))
+	span.SetStatus(codes.Ok, "data received")
+	return data, nil
+}
+
+// query performs instant query, decoding vector and matrix results series by series, so response limits
+// abort decoding before too many series are loaded into memory
+func (c *Client) query(ctx context.Context, span trace.Span, params doParams) (data QueryResult, err error) {
+	resp, err := c.do(ctx, "instant", params)
+	if err != nil {
+		return data, err
+	}
+	defer resp.Body.Close()
 	err = handleErrorResponse(resp, span)
 	if err != nil {
-		return nil, err
+		return data, err
 	}
 	span.AddEvent("request performed")
-	var raw instantRawResponse
-	err = json.NewDecoder(resp.Body).Decode(&raw)
+	var decoder *streamDecoder
+	decoder = newStreamDecoder(resp.Body, c.responseLimits, func(output Range) bool {
+		if decoder.resultType == ResultTypeMatrix {
+			data.Matrix = append(data.Matrix, output)
+			return true
+		}
+		// result type is not provided by old versions, which return vector
+		element := Instant{Labels: output.Labels}
+		if len(output.Values) > 0 {
+			element.Result = output.Values[0]
+		}
+		data.Vector = append(data.Vector, element)
+		return true
+	})
+	decoder.anyType = true
+	err = decoder.decode()
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
-		return nil, err
+		return data, err
 	}
 	span.AddEvent("body parsed")
-	if raw.Status != "success" {
-		err = fmt.Errorf("wrong status: %s", raw.Status)
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		return nil, err
-	}
-	data = make([]Instant, len(raw.Data.Result))
-	for i := range raw.Data.Result {
-		output, err = raw.Data.Result[i].convert()
+	span.SetAttributes(attribute.String("result_type", string(decoder.resultType)))
+	switch decoder.resultType {
+	case ResultTypeMatrix:
+		data.Type = ResultTypeMatrix
+		if data.Matrix == nil {
+			data.Matrix = []Range{}
+		}
+	case ResultTypeScalar, ResultTypeString:
+		raw := queryRespData{ResultType: decoder.resultType, Result: decoder.other}
+		data, err = raw.convert()
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
-			return nil, err
+			return data, err
+		}
+	case ResultTypeVector, "":
+		data.Type = ResultTypeVector
+		if data.Vector == nil {
+			data.Vector = []Instant{}
 		}
-		data[i] = output
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnexpectedResultType, decoder.resultType)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return data, err
 	}
-	span.SetStatus(codes.Ok, "data received")
 	return data, nil
 }
@@ -0,0 +1,88 @@
+package vmclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryResultTypesAgainstHttpMock(tt *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	responses := map[string]string{
+		"scalar(sum(up))":        `{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"42"]}}`,
+		`"hello"`:                `{"status":"success","data":{"resultType":"string","result":[1734677495.161,"hello"]}}`,
+		`something{job="x"}[5m]`: `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"something","job":"x"},"values":[[1734677495,"1"],[1734677555,"2"]]}]}}`,
+	}
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/query",
+		func(req *http.Request) (*http.Response, error) {
+			body, found := responses[req.URL.Query().Get("query")]
+			if !found {
+				return httpmock.NewStringResponse(http.StatusNotFound, "not found"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, body), nil
+		})
+
+	client, errC := New(tt.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+	})
+	if errC != nil {
+		tt.Errorf("error creating client: %s", errC)
+		return
+	}
+
+	tt.Run("scalar", func(t *testing.T) {
+		result, err := client.Query(t.Context(), "scalar(sum(up))", time.Now(), DefaultStep)
+		if err != nil {
+			t.Errorf("error sending query: %s", err)
+			return
+		}
+		assert.Equal(t, ResultTypeScalar, result.Type)
+		assert.Equal(t, float64(42), result.Scalar.Value)
+		assert.Equal(t, int64(1734677495161), result.Scalar.Timestamp.UnixMilli())
+	})
+
+	tt.Run("scalar via instant", func(t *testing.T) {
+		instants, err := client.Instant(t.Context(), "scalar(sum(up))", time.Now(), DefaultStep)
+		if err != nil {
+			t.Errorf("error sending query: %s", err)
+			return
+		}
+		assert.Len(t, instants, 1)
+		assert.Equal(t, float64(42), instants[0].Value)
+		assert.Empty(t, instants[0].Labels)
+	})
+
+	tt.Run("string", func(t *testing.T) {
+		result, err := client.Query(t.Context(), `"hello"`, time.Now(), DefaultStep)
+		if err != nil {
+			t.Errorf("error sending query: %s", err)
+			return
+		}
+		assert.Equal(t, ResultTypeString, result.Type)
+		assert.Equal(t, "hello", result.String.Value)
+	})
+
+	tt.Run("matrix", func(t *testing.T) {
+		result, err := client.Query(t.Context(), `something{job="x"}[5m]`, time.Now(), DefaultStep)
+		if err != nil {
+			t.Errorf("error sending query: %s", err)
+			return
+		}
+		assert.Equal(t, ResultTypeMatrix, result.Type)
+		assert.Len(t, result.Matrix, 1)
+		assert.Equal(t, "something", result.Matrix[0].Name())
+		assert.Len(t, result.Matrix[0].Values, 2)
+	})
+
+	tt.Run("matrix via instant", func(t *testing.T) {
+		instants, err := client.Instant(t.Context(), `something{job="x"}[5m]`, time.Now(), DefaultStep)
+		assert.Empty(t, instants)
+		assert.ErrorIs(t, err, ErrUnexpectedResultType)
+	})
+}
@@ -0,0 +1,154 @@
+package vmclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Limits restricts rate and concurrency of requests
+type Limits struct {
+	// Rate is maximal number of requests per second, zero means unlimited
+	Rate float64
+	// Burst is number of requests, that can be sent at once exceeding Rate, one is used if zero
+	Burst int
+	// MaxInFlight is maximal number of requests performed simultaneously, zero means unlimited
+	MaxInFlight int
+}
+
+// limiter combines token bucket and semaphore
+type limiter struct {
+	rate  float64
+	burst float64
+	slots chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limits Limits) *limiter {
+	if limits.Rate <= 0 && limits.MaxInFlight <= 0 {
+		return nil
+	}
+	l := &limiter{rate: limits.Rate, burst: float64(max(limits.Burst, 1))}
+	l.tokens = l.burst
+	l.last = time.Now()
+	if limits.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limits.MaxInFlight)
+	}
+	return l
+}
+
+// reserve takes token from bucket and returns delay, after which request can be sent
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// unreserve returns token taken by request, which is not sent
+func (l *limiter) unreserve() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// acquire waits until request is allowed by limits or context is done. Returned function must be called,
+// when request is finished. Time spent waiting is recorded on span.
+func (l *limiter) acquire(ctx context.Context, span trace.Span) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	started := time.Now()
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}
+	if l.rate > 0 {
+		delay := l.reserve()
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				l.unreserve()
+				release()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+	span.SetAttributes(attribute.String("queue_time", time.Since(started).String()))
+	return release, nil
+}
+
+// tryAcquire takes slot and token for request without waiting, false is returned, if request is not allowed now.
+// Returned function must be called, when request is finished.
+func (l *limiter) tryAcquire() (release func(), ok bool) {
+	if l == nil {
+		return func() {}, true
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			return nil, false
+		}
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}
+	if l.rate > 0 && l.reserve() > 0 {
+		l.unreserve()
+		release()
+		return nil, false
+	}
+	return release, true
+}
+
+// limited performs request allowed by limiter, keeping its slot until response body is closed
+func limited(ctx context.Context, l *limiter, perform func() (*http.Response, error)) (resp *http.Response, err error) {
+	span := trace.SpanFromContext(ctx)
+	release, err := l.acquire(ctx, span)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	resp, err = perform()
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: release}
+	return resp, nil
+}
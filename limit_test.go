@@ -0,0 +1,87 @@
+package vmclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLimiter(t *testing.T) {
+	assert.Nil(t, newLimiter(Limits{}))
+
+	l := newLimiter(Limits{Rate: 100, Burst: 2})
+	span := trace.SpanFromContext(t.Context())
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := l.acquire(t.Context(), span)
+		if assert.NoError(t, err) {
+			release()
+		}
+	}
+	assert.GreaterOrEqual(t, time.Since(started), 15*time.Millisecond, "rate is not limited")
+
+	l = newLimiter(Limits{MaxInFlight: 1})
+	release, err := l.acquire(t.Context(), span)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.acquire(ctx, span)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	release()
+	release()
+	release, err = l.acquire(t.Context(), span)
+	if assert.NoError(t, err, "slot is not released") {
+		release()
+	}
+}
+
+func TestQueryLimitsAgainstHttpMock(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query`,
+		func(req *http.Request) (*http.Response, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				known := maxInFlight.Load()
+				if current <= known || maxInFlight.CompareAndSwap(known, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:     DefaultEndpoint,
+		HttpClient:  &http.Client{Transport: mockTransport},
+		QueryLimits: Limits{MaxInFlight: 2},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+			assert.NoError(t, errQ)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), maxInFlight.Load())
+}
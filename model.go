@@ -3,6 +3,7 @@ package vmclient
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +26,37 @@ func labelsToString(labels map[string]string) string {
 	return name + "{" + strings.Join(elems, ",") + "}"
 }
 
+// parseLabels parses comma-separated list of labels like `job="vmclient",unit="test"`
+func parseLabels(input string) (labels map[string]string, err error) {
+	labels = make(map[string]string)
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		n := strings.IndexByte(rest, '=')
+		if n <= 0 {
+			return nil, fmt.Errorf("missing label name in %q", input)
+		}
+		name := strings.TrimSpace(rest[:n])
+		rest = strings.TrimSpace(rest[n+1:])
+		quoted, errQuoted := strconv.QuotedPrefix(rest)
+		if errQuoted != nil {
+			return nil, fmt.Errorf("error parsing value of label %s in %q: %w", name, input, errQuoted)
+		}
+		value, errUnquote := strconv.Unquote(quoted)
+		if errUnquote != nil {
+			return nil, fmt.Errorf("error parsing value of label %s in %q: %w", name, input, errUnquote)
+		}
+		labels[name] = value
+		rest = strings.TrimSpace(rest[len(quoted):])
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("missing comma after label %s in %q", name, input)
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+	return labels, nil
+}
+
 type Result struct {
 	Value     float64
 	Timestamp time.Time
@@ -63,3 +95,33 @@ func (r *Range) Name() string {
 func (r *Range) String() string {
 	return labelsToString(r.Labels)
 }
+
+// ResultType is type of data returned by query, as described here
+// https://prometheus.io/docs/prometheus/latest/querying/api/#expression-query-result-formats
+type ResultType string
+
+const (
+	// ResultTypeVector means query returned set of time series with single sample each
+	ResultTypeVector ResultType = "vector"
+	// ResultTypeMatrix means query returned set of time series with many samples each
+	ResultTypeMatrix ResultType = "matrix"
+	// ResultTypeScalar means query returned single numeric value
+	ResultTypeScalar ResultType = "scalar"
+	// ResultTypeString means query returned single string value
+	ResultTypeString ResultType = "string"
+)
+
+// StringResult is string value returned by query like `"something"`
+type StringResult struct {
+	Value     string
+	Timestamp time.Time
+}
+
+// QueryResult is result of instant query of any type. Only field matching Type is populated.
+type QueryResult struct {
+	Type   ResultType
+	Vector []Instant
+	Matrix []Range
+	Scalar Result
+	String StringResult
+}
@@ -0,0 +1,114 @@
+package vmclient
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryOptions struct {
+	noCache       bool
+	roundDigits   *int
+	extraLabels   []string
+	extraFilters  []string
+	latencyOffset *time.Duration
+	limit         int
+	bypassCache   bool
+}
+
+// QueryOption sets per-query parameters of Instant, Query and Range, described here
+// https://docs.victoriametrics.com/victoriametrics/single-server-victoriametrics/#prometheus-querying-api-enhancements
+type QueryOption func(*queryOptions)
+
+// WithNoCache disables response cache of Victoria Metrics for this query. Result cache of client is bypassed too.
+func WithNoCache() QueryOption {
+	return func(o *queryOptions) {
+		o.noCache = true
+	}
+}
+
+// WithRoundDigits makes Victoria Metrics round values to given number of decimal digits after the point
+func WithRoundDigits(digits int) QueryOption {
+	return func(o *queryOptions) {
+		o.roundDigits = &digits
+	}
+}
+
+// WithExtraLabel adds label filter {name="value"} to every time series selector of query
+func WithExtraLabel(name, value string) QueryOption {
+	return func(o *queryOptions) {
+		o.extraLabels = append(o.extraLabels, name+"="+value)
+	}
+}
+
+// WithExtraFilters adds series selectors like `{env=~"prod|dev",team!="devops"}` to every time series selector
+// of query. Selectors are applied with "or" between them
+func WithExtraFilters(filters ...string) QueryOption {
+	return func(o *queryOptions) {
+		o.extraFilters = append(o.extraFilters, filters...)
+	}
+}
+
+// WithLatencyOffset overrides -search.latencyOffset flag of Victoria Metrics for this query,
+// zero offset makes the freshest samples returned
+func WithLatencyOffset(offset time.Duration) QueryOption {
+	return func(o *queryOptions) {
+		o.latencyOffset = &offset
+	}
+}
+
+// WithLimit limits number of time series returned
+func WithLimit(limit int) QueryOption {
+	return func(o *queryOptions) {
+		o.limit = limit
+	}
+}
+
+// WithCacheBypass makes Range query ignore result cache of client, while response cache of Victoria Metrics is used
+func WithCacheBypass() QueryOption {
+	return func(o *queryOptions) {
+		o.bypassCache = true
+	}
+}
+
+func newQueryOptions(opts []QueryOption) (o queryOptions) {
+	for i := range opts {
+		opts[i](&o)
+	}
+	return o
+}
+
+// apply sets query arguments of options and records them on span, label filters are redacted, if policy requires
+func (o *queryOptions) apply(args url.Values, span trace.Span, redaction RedactionPolicy) {
+	if o.noCache {
+		args.Set("nocache", "1")
+		span.SetAttributes(attribute.Bool("nocache", true))
+	}
+	if o.roundDigits != nil {
+		args.Set("round_digits", strconv.Itoa(*o.roundDigits))
+		span.SetAttributes(attribute.Int("round_digits", *o.roundDigits))
+	}
+	for i := range o.extraLabels {
+		args.Add("extra_label", o.extraLabels[i])
+	}
+	if len(o.extraLabels) > 0 {
+		span.SetAttributes(redaction.values("extra_label", o.extraLabels)...)
+	}
+	for i := range o.extraFilters {
+		args.Add("extra_filters[]", o.extraFilters[i])
+	}
+	if len(o.extraFilters) > 0 {
+		span.SetAttributes(redaction.values("extra_filters", o.extraFilters)...)
+	}
+	if o.latencyOffset != nil {
+		args.Set("latency_offset", o.latencyOffset.String())
+		span.SetAttributes(attribute.String("latency_offset", o.latencyOffset.String()))
+	}
+	if o.limit > 0 {
+		args.Set("limit", strconv.Itoa(o.limit))
+		span.SetAttributes(attribute.Int("limit", o.limit))
+	}
+}
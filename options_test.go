@@ -0,0 +1,67 @@
+package vmclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestQueryOptionsAgainstHttpMock(t *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/query_range",
+		func(req *http.Request) (*http.Response, error) {
+			args := req.URL.Query()
+			if args.Get("nocache") != "1" ||
+				args.Get("round_digits") != "0" ||
+				args.Get("latency_offset") != "30s" ||
+				args.Get("limit") != "10" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "wrong arguments "+args.Encode()), nil
+			}
+			if !assert.Equal(t, []string{"tenant=a", "env=prod"}, args["extra_label"]) ||
+				!assert.Equal(t, []string{`{team="devops"}`}, args["extra_filters[]"]) {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "wrong filters "+args.Encode()), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"matrix","result":[]}}`), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	_, err = client.Range(t.Context(), "something", time.Now().Add(-time.Hour), time.Now(), DefaultStep,
+		WithNoCache(),
+		WithRoundDigits(0),
+		WithExtraLabel("tenant", "a"),
+		WithExtraLabel("env", "prod"),
+		WithExtraFilters(`{team="devops"}`),
+		WithLatencyOffset(30*time.Second),
+		WithLimit(10),
+	)
+	assert.NoError(t, err)
+}
+
+func TestZeroLatencyOffset(t *testing.T) {
+	span := trace.SpanFromContext(context.Background())
+	args := url.Values{}
+	o := newQueryOptions([]QueryOption{WithLatencyOffset(0)})
+	o.apply(args, span, RedactionPolicy{})
+	assert.Equal(t, "0s", args.Get("latency_offset"), "zero latency offset is not sent")
+
+	args = url.Values{}
+	o = newQueryOptions(nil)
+	o.apply(args, span, RedactionPolicy{})
+	assert.False(t, args.Has("latency_offset"), "latency offset is sent without option")
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
 	"go.opentelemetry.io/otel/trace"
@@ -14,17 +14,43 @@ import (
 
 // Ping checks if database accepts connections
 func (c *Client) Ping(initialCtx context.Context) (err error) {
-	ctx, span := otel.GetTracerProvider().Tracer("vmclient").Start(initialCtx, "ping",
+	ctx, span := c.tracer.Start(initialCtx, "ping",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
 			semconv.DBSystemNameKey.String("Victoria Metrics")),
 	)
 	defer span.End()
+	defer c.telemetry.record(ctx, "ping", c.endpoint, time.Now(), &err)
 
-	resp, err := c.do(ctx, "ping", doParams{})
+	addresses := c.healthEndpoints()
+	if c.pool != nil {
+		err = c.pingPool(ctx, span)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	} else if len(addresses) == 0 {
+		err = fmt.Errorf("%w: address is empty", ErrNoEndpoint)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+	for i := range addresses {
+		err = c.ping(ctx, span, addresses[i])
+		if err != nil {
+			return err
+		}
+	}
+	span.SetStatus(codes.Ok, "database responding")
+	return nil
+}
+
+func (c *Client) ping(ctx context.Context, span trace.Span, address string) (err error) {
+	resp, err := c.do(ctx, "ping", doParams{address: address})
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		retErr := Err{
 			Err:     ErrUnexpectedResponse,
@@ -43,6 +69,6 @@ func (c *Client) Ping(initialCtx context.Context) (err error) {
 		span.RecordError(retErr)
 		return retErr
 	}
-	span.SetStatus(codes.Ok, "database responding")
+	span.AddEvent("database responding", trace.WithAttributes(semconv.ServerAddress(address)))
 	return nil
 }
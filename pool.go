@@ -0,0 +1,140 @@
+package vmclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BalancingPolicy defines how endpoint is picked for read request, when Config.Addresses has many of them
+type BalancingPolicy int
+
+const (
+	// BalanceRoundRobin picks healthy endpoints one by one
+	BalanceRoundRobin BalancingPolicy = iota
+	// BalanceLeastInFlight picks healthy endpoint with least number of requests being performed
+	BalanceLeastInFlight
+)
+
+type poolEndpoint struct {
+	address  string
+	healthy  atomic.Bool
+	inFlight atomic.Int64
+}
+
+type endpointPool struct {
+	endpoints []*poolEndpoint
+	policy    BalancingPolicy
+	counter   atomic.Uint64
+}
+
+func newEndpointPool(addresses []string, policy BalancingPolicy) *endpointPool {
+	p := &endpointPool{policy: policy}
+	for i := range addresses {
+		ep := &poolEndpoint{address: addresses[i]}
+		ep.healthy.Store(true)
+		p.endpoints = append(p.endpoints, ep)
+	}
+	return p
+}
+
+// pick returns endpoint for request, skipping ones already tried. Unhealthy endpoints are used only
+// if there are no healthy ones left.
+func (p *endpointPool) pick(tried map[*poolEndpoint]bool) (picked *poolEndpoint) {
+	offset := int(p.counter.Add(1) - 1)
+	var fallback *poolEndpoint
+	for i := range p.endpoints {
+		ep := p.endpoints[(offset+i)%len(p.endpoints)]
+		if tried[ep] {
+			continue
+		}
+		if !ep.healthy.Load() {
+			if fallback == nil {
+				fallback = ep
+			}
+			continue
+		}
+		if p.policy == BalanceRoundRobin {
+			return ep
+		}
+		if picked == nil || ep.inFlight.Load() < picked.inFlight.Load() {
+			picked = ep
+		}
+	}
+	if picked == nil {
+		return fallback
+	}
+	return picked
+}
+
+// doPool performs read request on endpoint picked from pool, failing over to other endpoints on network errors.
+// If first is not nil, it is used for first attempt.
+func (c *Client) doPool(ctx context.Context, operation string, params doParams, first *poolEndpoint) (resp *http.Response, err error) {
+	span := trace.SpanFromContext(ctx)
+	tried := make(map[*poolEndpoint]bool)
+	for {
+		ep := first
+		first = nil
+		if ep == nil {
+			ep = c.pool.pick(tried)
+		}
+		if ep == nil {
+			return resp, err
+		}
+		tried[ep] = true
+		params.address = ep.address
+		span.SetAttributes(semconv.DBClientConnectionPoolName(ep.address))
+		ep.inFlight.Add(1)
+		resp, err = c.doAddress(ctx, operation, params)
+		ep.inFlight.Add(-1)
+		var urlErr *url.Error
+		if err == nil || ctx.Err() != nil || !errors.As(err, &urlErr) {
+			return resp, err
+		}
+		ep.healthy.Store(false)
+		span.AddEvent("endpoint failed", trace.WithAttributes(
+			attribute.String("endpoint", ep.address),
+			attribute.String("error", err.Error()),
+		))
+	}
+}
+
+// pingPool checks every endpoint of pool and marks it healthy or not. Error is returned, if all endpoints fail.
+func (c *Client) pingPool(ctx context.Context, span trace.Span) error {
+	var errs []error
+	for _, ep := range c.pool.endpoints {
+		err := c.ping(ctx, span, ep.address)
+		ep.healthy.Store(err == nil)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(c.pool.endpoints) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// watchHealth periodically checks health of endpoints
+func (c *Client) watchHealth(ctx context.Context, done chan struct{}, interval time.Duration) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			c.Ping(pingCtx)
+			cancel()
+		}
+	}
+}
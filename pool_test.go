@@ -0,0 +1,86 @@
+package vmclient
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointPoolPick(t *testing.T) {
+	pool := newEndpointPool([]string{"a", "b", "c"}, BalanceRoundRobin)
+	var picked []string
+	for i := 0; i < 4; i++ {
+		picked = append(picked, pool.pick(nil).address)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, picked)
+
+	pool.endpoints[1].healthy.Store(false)
+	for i := 0; i < 6; i++ {
+		assert.NotEqual(t, "b", pool.pick(nil).address, "unhealthy endpoint is picked")
+	}
+	tried := map[*poolEndpoint]bool{pool.endpoints[0]: true, pool.endpoints[2]: true}
+	assert.Equal(t, "b", pool.pick(tried).address, "unhealthy endpoint is not used as fallback")
+	tried[pool.endpoints[1]] = true
+	assert.Nil(t, pool.pick(tried))
+
+	pool = newEndpointPool([]string{"a", "b", "c"}, BalanceLeastInFlight)
+	pool.endpoints[0].inFlight.Store(2)
+	pool.endpoints[1].inFlight.Store(1)
+	pool.endpoints[2].inFlight.Store(3)
+	assert.Equal(t, "b", pool.pick(nil).address)
+}
+
+func TestPoolAgainstHttpMock(t *testing.T) {
+	const first, second = "http://first:8428", "http://second:8428"
+	var down atomic.Bool
+	var queried [2]atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	for i, address := range []string{first, second} {
+		mockTransport.RegisterResponder(http.MethodGet, address+"/-/healthy",
+			httpmock.NewStringResponder(http.StatusOK, "OK"))
+		mockTransport.RegisterResponder(http.MethodGet, `=~^`+address+`/prometheus/api/v1/query`,
+			func(req *http.Request) (*http.Response, error) {
+				if i == 0 && down.Load() {
+					return nil, errors.New("connection refused")
+				}
+				queried[i].Add(1)
+				return httpmock.NewStringResponse(http.StatusOK,
+					`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+			})
+	}
+
+	client, err := New(t.Context(), Config{
+		Addresses:           []string{first, second},
+		HttpClient:          &http.Client{Transport: mockTransport},
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	defer client.Close(t.Context())
+
+	for i := 0; i < 4; i++ {
+		_, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		assert.NoError(t, errQ)
+	}
+	assert.Equal(t, int32(2), queried[0].Load())
+	assert.Equal(t, int32(2), queried[1].Load())
+
+	down.Store(true)
+	for i := 0; i < 4; i++ {
+		_, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		assert.NoError(t, errQ, "request is not failed over")
+	}
+	assert.Equal(t, int32(2), queried[0].Load())
+	assert.Equal(t, int32(6), queried[1].Load())
+	assert.False(t, client.pool.endpoints[0].healthy.Load())
+
+	assert.NoError(t, client.Ping(t.Context()))
+	assert.True(t, client.pool.endpoints[0].healthy.Load(), "endpoint is not marked healthy after ping")
+}
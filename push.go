@@ -1,24 +1,25 @@
 package vmclient
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/VictoriaMetrics/metrics"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// Push sends metrics set
-func (c *Client) Push(initialCtx context.Context, set *metrics.Set) error {
-	ctx, span := otel.GetTracerProvider().Tracer("vmclient").Start(initialCtx, "push",
+// Push sends metrics set in Prometheus text exposition format
+func (c *Client) Push(initialCtx context.Context, set *metrics.Set) (err error) {
+	ctx, span := c.tracer.Start(initialCtx, "push",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(
-			semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBClientConnectionPoolName(c.insertEndpoint),
 			semconv.DBSystemNameKey.String("Victoria Metrics"),
 			attribute.String("extra_labels", c.extraLabels),
 			attribute.StringSlice("metric.names", set.ListMetricNames()),
@@ -26,28 +27,38 @@ func (c *Client) Push(initialCtx context.Context, set *metrics.Set) error {
 		),
 	)
 	defer span.End()
+	defer c.telemetry.record(ctx, "push", c.insertEndpoint, time.Now(), &err)
 
-	endpoint, err := url.JoinPath(c.endpoint, DefaultPushEndpoint)
+	u, err := c.insertURL(DefaultPushEndpoint)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
 		return err
 	}
-	var i int
-	headers := make([]string, len(c.headers))
-	for k, v := range c.headers {
-		headers[i] = k + ": " + v
-		span.SetAttributes(semconv.HTTPRequestHeader(k, v))
-		i++
+	c.setExtraLabels(u)
+	if c.cluster {
+		span.SetAttributes(attribute.String("tenant", c.tenant.String()))
 	}
-	err = set.PushMetrics(ctx, endpoint, &metrics.PushOptions{
-		ExtraLabels: c.extraLabels,
-		Headers:     headers,
-		Method:      http.MethodPost,
-	})
+	buf := bytes.Buffer{}
+	set.WritePrometheus(&buf)
+	encoding := c.compression.bodyEncoding("gzip")
+	body, err := compress(encoding, buf.Bytes())
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 		return err
 	}
+	span.SetAttributes(
+		attribute.Int("body.uncompressed", buf.Len()),
+		attribute.Int("body.compressed", len(body)),
+	)
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain")
+	header.Set("Content-Encoding", encoding)
+	err = c.deliver(ctx, span, "push", u, body, header)
+	if err != nil {
+		return err
+	}
 	span.SetStatus(codes.Ok, "metrics are pushed")
 	return nil
 }
@@ -67,3 +78,15 @@ func (c *Client) PushCounter(ctx context.Context, name string, value uint64) err
 	set.GetOrCreateCounter(name).Set(value)
 	return c.Push(ctx, set)
 }
+
+// setExtraLabels adds extra labels from Config.ExtraLabels to query of ingestion API url
+func (c *Client) setExtraLabels(u *url.URL) {
+	if len(c.extraLabelSet) == 0 {
+		return
+	}
+	args := u.Query()
+	for k, v := range c.extraLabelSet {
+		args.Add("extra_label", k+"="+v)
+	}
+	u.RawQuery = args.Encode()
+}
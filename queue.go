@@ -0,0 +1,345 @@
+package vmclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const queueFileExtension = ".batch"
+
+// queueEntry is request to ingestion API persisted on disk
+type queueEntry struct {
+	// Path is path with query of request relative to insert address
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header"`
+	Body   []byte            `json:"-"`
+}
+
+type queueFile struct {
+	seq  uint64
+	size int64
+}
+
+// diskQueue is file-backed FIFO queue, where every entry is stored in separate file named by its sequence number.
+// When total size of files exceeds limit, oldest entries are dropped, like vmagent persistent queue does.
+type diskQueue struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+	files    []queueFile
+	size     int64
+	next     uint64
+	dropped  atomic.Uint64
+}
+
+func openDiskQueue(dir string, maxBytes int64) (q *diskQueue, err error) {
+	err = os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("error creating queue directory: %w", err)
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading queue directory: %w", err)
+	}
+	q = &diskQueue{dir: dir, maxBytes: maxBytes}
+	for i := range dirEntries {
+		name := dirEntries[i].Name()
+		if strings.HasSuffix(name, queueFileExtension+".tmp") {
+			// leftover of entry, which was being written, when process crashed
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if dirEntries[i].IsDir() || !strings.HasSuffix(name, queueFileExtension) {
+			continue
+		}
+		seq, errParsing := strconv.ParseUint(strings.TrimSuffix(name, queueFileExtension), 16, 64)
+		if errParsing != nil {
+			continue
+		}
+		info, errInfo := dirEntries[i].Info()
+		if errInfo != nil {
+			return nil, fmt.Errorf("error reading queue file %s: %w", name, errInfo)
+		}
+		q.files = append(q.files, queueFile{seq: seq, size: info.Size()})
+		q.size += info.Size()
+	}
+	sort.Slice(q.files, func(i, j int) bool {
+		return q.files[i].seq < q.files[j].seq
+	})
+	if len(q.files) > 0 {
+		q.next = q.files[len(q.files)-1].seq + 1
+	}
+	return q, nil
+}
+
+func (q *diskQueue) filename(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%016x%s", seq, queueFileExtension))
+}
+
+func (q *diskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.files)
+}
+
+// push appends entry to the end of queue, dropping oldest entries if size limit is exceeded
+func (q *diskQueue) push(entry queueEntry) error {
+	header, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	seq := q.next
+	name := q.filename(seq)
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, err = w.Write(append(header, '\n'))
+	if err == nil {
+		_, err = w.Write(entry.Body)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	errClose := f.Close()
+	if err == nil {
+		err = errClose
+	}
+	if err == nil {
+		// rename is atomic, so partially written entries are never replayed
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing queue file: %w", err)
+	}
+	size := int64(len(header) + 1 + len(entry.Body))
+	q.next++
+	q.files = append(q.files, queueFile{seq: seq, size: size})
+	q.size += size
+	for q.maxBytes > 0 && q.size > q.maxBytes && len(q.files) > 1 {
+		err = os.Remove(q.filename(q.files[0].seq))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error dropping oldest queue file: %w", err)
+		}
+		q.size -= q.files[0].size
+		q.files = q.files[1:]
+		q.dropped.Add(1)
+	}
+	return nil
+}
+
+// peek returns oldest entry without removing it
+func (q *diskQueue) peek() (entry queueEntry, seq uint64, found bool, err error) {
+	q.mu.Lock()
+	if len(q.files) == 0 {
+		q.mu.Unlock()
+		return entry, 0, false, nil
+	}
+	seq = q.files[0].seq
+	q.mu.Unlock()
+	raw, err := os.ReadFile(q.filename(seq))
+	if err != nil {
+		return entry, seq, true, err
+	}
+	header, body, ok := bytes.Cut(raw, []byte{'\n'})
+	if !ok {
+		return entry, seq, true, fmt.Errorf("malformed queue file %s", q.filename(seq))
+	}
+	err = json.Unmarshal(header, &entry)
+	if err != nil {
+		return entry, seq, true, fmt.Errorf("malformed queue file %s: %w", q.filename(seq), err)
+	}
+	entry.Body = body
+	return entry, seq, true, nil
+}
+
+// remove deletes entry from the head of queue
+func (q *diskQueue) remove(seq uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.files) == 0 || q.files[0].seq != seq {
+		// entry was dropped, because queue was overflown
+		return nil
+	}
+	err := os.Remove(q.filename(seq))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	q.size -= q.files[0].size
+	q.files = q.files[1:]
+	return nil
+}
+
+// shouldPersist returns true, if request can succeed later, when database is available again
+func shouldPersist(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var vmErr Err
+	if errors.As(err, &vmErr) {
+		return vmErr.Code >= http.StatusInternalServerError || vmErr.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// deliver sends body to ingestion API. If disk queue is configured, body is persisted in it, when database
+// is unavailable, or when queue is not empty, so order of data sent is preserved.
+func (c *Client) deliver(ctx context.Context, span trace.Span, operation string, u *url.URL, body []byte,
+	header http.Header) error {
+	if c.queue != nil && c.queue.len() > 0 {
+		return c.persist(span, u, body, header, nil)
+	}
+	resp, err := c.write(ctx, operation, u, bytes.NewReader(body), header)
+	if err == nil {
+		defer resp.Body.Close()
+		err = handleErrorResponse(resp, span)
+		if err == nil {
+			return nil
+		}
+	}
+	if c.queue == nil || !shouldPersist(err) {
+		return err
+	}
+	return c.persist(span, u, body, header, err)
+}
+
+func (c *Client) persist(span trace.Span, u *url.URL, body []byte, header http.Header, reason error) error {
+	entry := queueEntry{Path: strings.TrimPrefix(u.RequestURI(), c.insertPathPrefix()), Header: make(map[string]string), Body: body}
+	for k := range header {
+		entry.Header[k] = header.Get(k)
+	}
+	err := c.queue.push(entry)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		if reason != nil {
+			return errors.Join(reason, err)
+		}
+		return err
+	}
+	span.AddEvent("request persisted in disk queue", trace.WithAttributes(attribute.Int("body.size", len(body))))
+	return nil
+}
+
+// insertPathPrefix returns path of insert address, that is not stored in queue entries
+func (c *Client) insertPathPrefix() string {
+	u, err := url.Parse(c.insertEndpoint)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// replayQueue periodically sends entries persisted in disk queue, when Ping succeeds
+func (c *Client) replayQueue(ctx context.Context, done chan struct{}, interval time.Duration) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.queue.len() > 0 {
+				c.drainQueue(ctx)
+			}
+		}
+	}
+}
+
+// drainQueue sends entries of disk queue in order, until queue is empty or request fails
+func (c *Client) drainQueue(initialCtx context.Context) {
+	ctx, span := c.tracer.Start(initialCtx, "replay",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.insertEndpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+	defer span.End()
+
+	err := c.Ping(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	var replayed int
+	for {
+		entry, seq, found, err := c.queue.peek()
+		if !found {
+			break
+		}
+		if err != nil {
+			// corrupted entries are removed, since they cannot be sent ever
+			span.RecordError(err)
+		} else {
+			err = c.replay(ctx, span, entry)
+			if err != nil && shouldPersist(err) {
+				span.SetAttributes(attribute.Int("replayed", replayed))
+				span.SetStatus(codes.Error, err.Error())
+				span.RecordError(err)
+				return
+			}
+			// entries rejected by database are removed too, since they cannot succeed ever
+		}
+		err = c.queue.remove(seq)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return
+		}
+		replayed++
+	}
+	span.SetAttributes(attribute.Int("replayed", replayed))
+	span.SetStatus(codes.Ok, "queue is replayed")
+}
+
+func (c *Client) replay(ctx context.Context, span trace.Span, entry queueEntry) error {
+	u, err := url.Parse(strings.TrimSuffix(c.insertEndpoint, "/") + entry.Path)
+	if err != nil {
+		return Err{Code: http.StatusBadRequest, Message: "malformed queue entry", Err: err}
+	}
+	header := http.Header{}
+	for k, v := range entry.Header {
+		header.Set(k, v)
+	}
+	resp, err := c.write(ctx, "replay", u, bytes.NewReader(entry.Body), header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return handleErrorResponse(resp, span)
+}
+
+// Queued returns number of requests waiting in disk queue
+func (c *Client) Queued() int {
+	if c.queue == nil {
+		return 0
+	}
+	return c.queue.len()
+}
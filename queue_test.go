@@ -0,0 +1,109 @@
+package vmclient
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openDiskQueue(dir, 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"first", "second", "third"} {
+		err = q.push(queueEntry{Path: "/api/v1/write", Header: map[string]string{"A": "b"}, Body: []byte(body)})
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	assert.Equal(t, 2, q.len(), "oldest entry is not dropped")
+	assert.Equal(t, uint64(1), q.dropped.Load())
+
+	reopened, err := openDiskQueue(dir, 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, reopened.len())
+	entry, seq, found, err := reopened.peek()
+	if assert.NoError(t, err) && assert.True(t, found) {
+		assert.Equal(t, "/api/v1/write", entry.Path)
+		assert.Equal(t, "b", entry.Header["A"])
+		assert.Equal(t, "second", string(entry.Body))
+	}
+	assert.NoError(t, reopened.remove(seq))
+	entry, _, _, err = reopened.peek()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "third", string(entry.Body))
+	}
+	assert.NoError(t, reopened.push(queueEntry{Path: "/api/v1/write", Body: []byte("fourth")}))
+	assert.Equal(t, 2, reopened.len())
+}
+
+func TestDiskQueueAgainstHttpMock(t *testing.T) {
+	var available atomic.Bool
+	var received []string
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		func(req *http.Request) (*http.Response, error) {
+			if !available.Load() {
+				return httpmock.NewStringResponse(http.StatusServiceUnavailable, "unavailable"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, "OK"), nil
+		})
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultPushEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			if !available.Load() {
+				return httpmock.NewStringResponse(http.StatusServiceUnavailable, "unavailable"), nil
+			}
+			zr, err := gzip.NewReader(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			body, err := io.ReadAll(zr)
+			if err != nil {
+				return nil, err
+			}
+			if req.URL.Query().Get("extra_label") != "unit=test" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "extra labels are lost"), nil
+			}
+			received = append(received, strings.TrimSpace(string(body)))
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:             DefaultEndpoint,
+		ExtraLabels:         `unit="test"`,
+		HttpClient:          &http.Client{Transport: mockTransport},
+		QueuePath:           t.TempDir(),
+		QueueReplayInterval: time.Hour,
+	})
+	if err != nil {
+		t.Errorf("client is not created, when database is unavailable: %s", err)
+		return
+	}
+	for i := uint64(1); i <= 2; i++ {
+		set := metrics.NewSet()
+		set.GetOrCreateCounter(`something{job="vmclient"}`).Set(i)
+		assert.NoError(t, client.Push(t.Context(), set))
+	}
+	assert.Equal(t, 2, client.Queued())
+
+	client.drainQueue(t.Context())
+	assert.Equal(t, 2, client.Queued(), "queue is replayed, when database is unavailable")
+
+	available.Store(true)
+	client.drainQueue(t.Context())
+	assert.Equal(t, 0, client.Queued())
+	assert.Equal(t, []string{`something{job="vmclient"} 1`, `something{job="vmclient"} 2`}, received)
+	assert.NoError(t, client.Close(t.Context()))
+}
@@ -2,12 +2,10 @@ package vmclient
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
 	"go.opentelemetry.io/otel/trace"
@@ -42,64 +40,88 @@ func parseRangeValue(input []any) (Result, error) {
 	return ret, nil
 }
 
-type rangeRespData struct {
-	Result []rangeResult `json:"result"`
-}
-
-type rangeRawResponse struct {
-	Status string        `json:"status"`
-	Data   rangeRespData `json:"data"`
+func (m *rangeResult) convert() (output Range, err error) {
+	values := make([]Result, len(m.Values))
+	for j := range m.Values {
+		values[j], err = parseRangeValue(m.Values[j])
+		if err != nil {
+			return output, err
+		}
+	}
+	output.Labels = m.Metric
+	output.Values = values
+	return output, nil
 }
 
 // Range makes range query as described here https://docs.victoriametrics.com/victoriametrics/keyconcepts/#range-query
-func (c *Client) Range(initialCtx context.Context, query string, start, end time.Time, step time.Duration) (data []Range, err error) {
-	ctx, span := otel.GetTracerProvider().Tracer("vmclient").Start(initialCtx, "range",
+// If Config.MaxPointsPerRequest is set and query requires more points per time series, time window is split into
+// step-aligned chunks, which are queried concurrently and merged.
+func (c *Client) Range(initialCtx context.Context, query string, start, end time.Time, step time.Duration, opts ...QueryOption) (data []Range, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "range",
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
 			semconv.DBSystemNameKey.String("Victoria Metrics")),
 	)
 	defer span.End()
+	defer c.telemetry.record(ctx, "range", c.endpoint, time.Now(), &err)
+
+	params := doParams{query: query, start: start, end: end, step: step, opts: newQueryOptions(opts)}
+	// cached results are aligned to step in milliseconds, so shorter steps are not cached
+	if c.cache != nil && step >= time.Millisecond && !params.opts.noCache && !params.opts.bypassCache {
+		data, err = c.rangeCached(ctx, span, params)
+	} else {
+		data, err = c.rangeFetch(ctx, span, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.telemetry.returned(ctx, "range", c.endpoint, len(data))
+	span.SetStatus(codes.Ok, "data received")
+	return data, nil
+}
 
-	var result Result
+// rangeFetch performs range query, splitting it into chunks, if it has too many points
+func (c *Client) rangeFetch(ctx context.Context, span trace.Span, params doParams) (data []Range, err error) {
+	if c.maxPointsPerRequest > 0 && params.step > 0 &&
+		params.end.Sub(params.start)/params.step >= time.Duration(c.maxPointsPerRequest) {
+		data, err = c.rangeSplit(ctx, span, params)
+		if err != nil {
+			return nil, err
+		}
+		// every chunk is limited separately, so merged data is checked too
+		counter := responseCounter{limits: c.responseLimits}
+		err = counter.countRanges(data)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
+		return data, nil
+	}
+	return c.rangeQuery(ctx, span, params)
+}
 
-	resp, err := c.do(ctx, "range", doParams{query: query, start: start, end: end, step: step})
+func (c *Client) rangeQuery(ctx context.Context, span trace.Span, params doParams) (data []Range, err error) {
+	resp, err := c.do(ctx, "range", params)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = handleErrorResponse(resp, span)
 	if err != nil {
 		return nil, err
 	}
 	span.AddEvent("request performed")
-	var raw rangeRawResponse
-	err = json.NewDecoder(resp.Body).Decode(&raw)
+	data = []Range{}
+	err = newStreamDecoder(resp.Body, c.responseLimits, func(output Range) bool {
+		data = append(data, output)
+		return true
+	}).decode()
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 		return nil, err
 	}
 	span.AddEvent("body parsed")
-	if raw.Status != "success" {
-		err = fmt.Errorf("wrong status: %s", raw.Status)
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		return nil, err
-	}
-	data = make([]Range, len(raw.Data.Result))
-	for i := range raw.Data.Result {
-		values := make([]Result, len(raw.Data.Result[i].Values))
-		for j := range raw.Data.Result[i].Values {
-			result, err = parseRangeValue(raw.Data.Result[i].Values[j])
-			if err != nil {
-				return nil, err
-			}
-			values[j] = result
-		}
-		data[i] = Range{
-			Labels: raw.Data.Result[i].Metric,
-			Values: values,
-		}
-	}
-	span.SetStatus(codes.Ok, "data received")
 	return data, nil
 }
@@ -0,0 +1,172 @@
+package vmclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedactionMode defines how sensitive values are hidden in span attributes
+type RedactionMode int
+
+const (
+	// RedactMask replaces sensitive value with RedactedValue
+	RedactMask RedactionMode = iota
+	// RedactHash replaces sensitive value with prefix of its SHA-256 hash, so equal values can be correlated
+	RedactHash
+	// RedactDrop does not record sensitive value at all
+	RedactDrop
+)
+
+// RedactedValue replaces sensitive values in span attributes, when RedactMask mode is used
+const RedactedValue = "REDACTED"
+
+// DefaultRedactedHeaders are patterns of names of headers, which values are not recorded in span attributes as is
+var DefaultRedactedHeaders = []string{"authorization", "cookie", "*token*"}
+
+// RedactionPolicy defines, which values recorded in span attributes are sensitive
+type RedactionPolicy struct {
+	// Denylist are case-insensitive shell patterns of names of headers, which values are redacted,
+	// like "x-*-key". DefaultRedactedHeaders are used if empty.
+	Denylist []string
+	// Allowlist are patterns of names of headers recorded as is, even if they match Denylist
+	Allowlist []string
+	// Mode defines how sensitive values are hidden, they are masked by default
+	Mode RedactionMode
+	// RedactQuery makes query text, series selectors and label filters to be redacted too. Query strings of urls
+	// are removed from spans of transport of client, unless Config.HttpClient is used, and redacted in errors
+	// of requests, so they are neither recorded in spans nor returned.
+	RedactQuery bool
+}
+
+func (p RedactionPolicy) withDefaults() RedactionPolicy {
+	if len(p.Denylist) == 0 {
+		p.Denylist = DefaultRedactedHeaders
+	}
+	return p
+}
+
+func matchesAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for i := range patterns {
+		matched, err := path.Match(strings.ToLower(patterns[i]), name)
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redact hides value according to mode, false is returned, if value should not be recorded
+func (p RedactionPolicy) redact(value string) (string, bool) {
+	switch p.Mode {
+	case RedactHash:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:8]), true
+	case RedactDrop:
+		return "", false
+	default:
+		return RedactedValue, true
+	}
+}
+
+// setHeaders records headers as span attributes, redacting sensitive ones
+func (p RedactionPolicy) setHeaders(span trace.Span, headers map[string]string) {
+	for k, v := range headers {
+		if matchesAny(p.Denylist, k) && !matchesAny(p.Allowlist, k) {
+			var record bool
+			v, record = p.redact(v)
+			if !record {
+				continue
+			}
+		}
+		// semantic conventions require lowercase header names in attribute keys
+		span.SetAttributes(semconv.HTTPRequestHeader(strings.ToLower(k), v))
+	}
+}
+
+// queryText returns attributes of query text, redacted, if policy requires
+func (p RedactionPolicy) queryText(query string) []attribute.KeyValue {
+	if !p.RedactQuery {
+		return []attribute.KeyValue{semconv.DBQueryText(query)}
+	}
+	redacted, record := p.redact(query)
+	if !record {
+		return nil
+	}
+	return []attribute.KeyValue{semconv.DBQueryText(redacted)}
+}
+
+// values returns attribute with list of series selectors or label filters, redacted, if policy requires
+func (p RedactionPolicy) values(key string, values []string) []attribute.KeyValue {
+	if !p.RedactQuery {
+		return []attribute.KeyValue{attribute.StringSlice(key, values)}
+	}
+	redacted := make([]string, 0, len(values))
+	for i := range values {
+		value, record := p.redact(values[i])
+		if !record {
+			return nil
+		}
+		redacted = append(redacted, value)
+	}
+	return []attribute.KeyValue{attribute.StringSlice(key, redacted)}
+}
+
+// redactError hides query string of url in error of request, if policy requires, since http.Client includes
+// url requested in errors, which are recorded in spans
+func (p RedactionPolicy) redactError(err error) error {
+	var urlErr *url.Error
+	if !p.RedactQuery || !errors.As(err, &urlErr) {
+		return err
+	}
+	u, errParse := url.Parse(urlErr.URL)
+	if errParse != nil || u.RawQuery == "" {
+		return err
+	}
+	u.RawQuery, _ = p.redact(u.RawQuery)
+	return &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+}
+
+// rawQueryKey is key of context value keeping query string hidden by queryHidingTransport
+type rawQueryKey struct{}
+
+// queryHidingTransport removes query string from url of request, so it is not recorded by instrumentation
+// of transport wrapped, like url.full attribute of otelhttp spans. Query string is kept in context of request
+// to be restored by queryRestoringTransport.
+type queryHidingTransport struct {
+	http.RoundTripper
+}
+
+func (t queryHidingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.RawQuery == "" {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	hidden := req.Clone(context.WithValue(req.Context(), rawQueryKey{}, req.URL.RawQuery))
+	hidden.URL.RawQuery = ""
+	return t.RoundTripper.RoundTrip(hidden)
+}
+
+// queryRestoringTransport restores query string hidden by queryHidingTransport before request is sent
+type queryRestoringTransport struct {
+	http.RoundTripper
+}
+
+func (t queryRestoringTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	raw, found := req.Context().Value(rawQueryKey{}).(string)
+	if !found {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	restored := req.Clone(req.Context())
+	restored.URL.RawQuery = raw
+	return t.RoundTripper.RoundTrip(restored)
+}
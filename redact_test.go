@@ -0,0 +1,179 @@
+package vmclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordAttributes returns attributes set on span by record
+func recordAttributes(t *testing.T, record func(span trace.Span)) map[attribute.Key]attribute.Value {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := provider.Tracer("test").Start(t.Context(), "test")
+	record(span)
+	span.End()
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range recorder.Ended()[0].Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func hashed(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+func TestRedactionPolicy(t *testing.T) {
+	const query = `up{tenant="acme"}`
+	headers := map[string]string{
+		"Authorization": "Bearer secret",
+		"X-Auth-Token":  "secret",
+		"X-Scope-Token": "tenant",
+		"X-Request-Id":  "42",
+	}
+	for name, testCase := range map[string]struct {
+		policy   RedactionPolicy
+		expected map[string]string
+		query    string
+	}{
+		"mask": {
+			policy: RedactionPolicy{Allowlist: []string{"x-scope-*"}},
+			expected: map[string]string{
+				"authorization": RedactedValue,
+				"x-auth-token":  RedactedValue,
+				"x-scope-token": "tenant",
+				"x-request-id":  "42",
+			},
+			query: query,
+		},
+		"hash": {
+			policy: RedactionPolicy{Mode: RedactHash, RedactQuery: true},
+			expected: map[string]string{
+				"authorization": hashed("Bearer secret"),
+				"x-auth-token":  hashed("secret"),
+				"x-scope-token": hashed("tenant"),
+				"x-request-id":  "42",
+			},
+			query: hashed(query),
+		},
+		"drop": {
+			policy:   RedactionPolicy{Mode: RedactDrop, Denylist: []string{"x-*"}, RedactQuery: true},
+			expected: map[string]string{"authorization": "Bearer secret"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy := testCase.policy.withDefaults()
+			attributes := recordAttributes(t, func(span trace.Span) {
+				policy.setHeaders(span, headers)
+				span.SetAttributes(policy.queryText(query)...)
+			})
+			for header, value := range testCase.expected {
+				recorded, found := attributes[attribute.Key("http.request.header."+header)]
+				if assert.True(t, found, header) {
+					assert.Equal(t, []string{value}, recorded.AsStringSlice(), header)
+				}
+			}
+			recorded, found := attributes["db.query.text"]
+			if testCase.query == "" {
+				assert.False(t, found, "dropped query is recorded")
+				assert.Len(t, attributes, len(testCase.expected))
+			} else if assert.True(t, found) {
+				assert.Equal(t, testCase.query, recorded.AsString())
+				assert.Len(t, attributes, len(testCase.expected)+1)
+			}
+		})
+	}
+}
+
+func TestRedactionAgainstServer(t *testing.T) {
+	const secret = `tenant="secret42"`
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/healthy" {
+			w.Write([]byte("OK"))
+			return
+		}
+		received = append(received, r.URL.RawQuery)
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	client, err := New(t.Context(), Config{
+		Address:        server.URL,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Redaction:      RedactionPolicy{RedactQuery: true},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	_, err = client.Instant(t.Context(), `up{`+secret+`}`, time.Now(), time.Minute,
+		WithExtraFilters(`{`+secret+`}`), WithExtraLabel("tenant", "secret42"))
+	assert.NoError(t, err)
+	if assert.Len(t, received, 1) {
+		assert.Contains(t, received[0], "secret42", "query string is not sent")
+	}
+	spans := recorder.Ended()
+	assert.NotEmpty(t, spans)
+	for _, span := range spans {
+		for _, kv := range span.Attributes() {
+			assert.NotContains(t, kv.Value.Emit(), "secret42", "%s of span %s is not redacted", kv.Key, span.Name())
+		}
+	}
+}
+
+func TestRedactionOfFailedRequest(t *testing.T) {
+	const secret = `tenant="secret42"`
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~/prometheus/api/v1/query`,
+		httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	for _, mode := range []RedactionMode{RedactMask, RedactHash, RedactDrop} {
+		recorder := tracetest.NewSpanRecorder()
+		client, err := New(t.Context(), Config{
+			Address:        DefaultEndpoint,
+			HttpClient:     &http.Client{Transport: mockTransport},
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+			Redaction:      RedactionPolicy{RedactQuery: true, Mode: mode},
+		})
+		if err != nil {
+			t.Errorf("error creating client: %s", err)
+			return
+		}
+		_, err = client.Instant(t.Context(), `up{`+secret+`}`, time.Now(), time.Minute,
+			WithExtraFilters(`{`+secret+`}`))
+		assert.ErrorContains(t, err, "connection refused")
+		assert.NotContains(t, err.Error(), "secret42")
+		var urlErr *url.Error
+		assert.True(t, errors.As(err, &urlErr), "error of request is not kept")
+
+		spans := recorder.Ended()
+		assert.NotEmpty(t, spans)
+		for _, span := range spans {
+			assert.NotContains(t, span.Status().Description, "secret42", "status of span %s is not redacted", span.Name())
+			for _, event := range span.Events() {
+				for _, kv := range event.Attributes {
+					assert.NotContains(t, kv.Value.Emit(), "secret42", "%s of event %s of span %s is not redacted",
+						kv.Key, event.Name, span.Name())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package vmclient
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sample is single value of time series with explicit timestamp
+type Sample struct {
+	Labels    map[string]string
+	Timestamp time.Time
+	Value     float64
+}
+
+func (s *Sample) Name() string {
+	name, found := s.Labels[LabelForName]
+	if found {
+		return name
+	}
+	return ""
+}
+
+func (s *Sample) String() string {
+	return labelsToString(s.Labels)
+}
+
+// RemoteWrite sends samples using Prometheus remote write protocol - snappy compressed protobuf
+// WriteRequest, as described here https://prometheus.io/docs/specs/prw/remote_write_spec/
+// It is compressed by zstd instead, if Config.Compression is CompressionZstd.
+func (c *Client) RemoteWrite(initialCtx context.Context, samples []Sample) (err error) {
+	ctx, span := c.tracer.Start(initialCtx, "remote_write",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBClientConnectionPoolName(c.insertEndpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics"),
+			attribute.String("extra_labels", c.extraLabels),
+			attribute.Int("samples", len(samples)),
+		),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "remote_write", c.insertEndpoint, time.Now(), &err)
+
+	u, err := c.insertURL(DefaultRemoteWriteEndpoint)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return err
+	}
+	c.setExtraLabels(u)
+
+	raw := marshalWriteRequest(samples)
+	encoding := "snappy"
+	var body []byte
+	if c.compression == CompressionZstd {
+		encoding = "zstd"
+		body = zstdEncoder().EncodeAll(raw, nil)
+	} else {
+		body = snappy.Encode(nil, raw)
+	}
+	span.SetAttributes(
+		attribute.Int("body.uncompressed", len(raw)),
+		attribute.Int("body.compressed", len(body)),
+	)
+	span.AddEvent("write request encoded")
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-protobuf")
+	header.Set("Content-Encoding", encoding)
+	header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	err = c.deliver(ctx, span, "remote_write", u, body, header)
+	if err != nil {
+		return err
+	}
+	span.SetStatus(codes.Ok, "samples are written")
+	return nil
+}
+
+// marshalWriteRequest encodes samples as protobuf message prometheus.WriteRequest, grouping them into time series
+// by labels. See https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+func marshalWriteRequest(samples []Sample) []byte {
+	type series struct {
+		labels  []string
+		samples []Sample
+	}
+	var timeseries []*series
+	index := make(map[string]*series)
+	for i := range samples {
+		key := labelsToString(samples[i].Labels)
+		ts, found := index[key]
+		if !found {
+			ts = &series{}
+			// labels must be sorted by name, as protocol requires
+			for k := range samples[i].Labels {
+				ts.labels = append(ts.labels, k)
+			}
+			sort.Strings(ts.labels)
+			index[key] = ts
+			timeseries = append(timeseries, ts)
+		}
+		ts.samples = append(ts.samples, samples[i])
+	}
+
+	var buf, msg, field []byte
+	for _, ts := range timeseries {
+		msg = msg[:0]
+		labels := ts.samples[0].Labels
+		for _, name := range ts.labels {
+			field = field[:0]
+			field = appendProtoString(field, 1, name)
+			field = appendProtoString(field, 2, labels[name])
+			msg = appendProtoBytes(msg, 1, field)
+		}
+		for j := range ts.samples {
+			field = field[:0]
+			field = binary.AppendUvarint(field, 1<<3|1) // field 1, fixed64
+			field = binary.LittleEndian.AppendUint64(field, math.Float64bits(ts.samples[j].Value))
+			field = binary.AppendUvarint(field, 2<<3|0) // field 2, varint
+			field = binary.AppendUvarint(field, uint64(ts.samples[j].Timestamp.UnixMilli()))
+			msg = appendProtoBytes(msg, 2, field)
+		}
+		buf = appendProtoBytes(buf, 1, msg)
+	}
+	return buf
+}
+
+func appendProtoBytes(dst []byte, fieldNumber uint64, value []byte) []byte {
+	dst = binary.AppendUvarint(dst, fieldNumber<<3|2) // length-delimited
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...)
+}
+
+func appendProtoString(dst []byte, fieldNumber uint64, value string) []byte {
+	dst = binary.AppendUvarint(dst, fieldNumber<<3|2) // length-delimited
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...)
+}
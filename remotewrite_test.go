@@ -0,0 +1,81 @@
+package vmclient
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels(`job="vmclient", unit="te\"st,"`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"job": "vmclient", "unit": `te"st,`}, labels)
+	}
+	labels, err = parseLabels("")
+	if assert.NoError(t, err) {
+		assert.Empty(t, labels)
+	}
+	_, err = parseLabels(`job=vmclient`)
+	assert.Error(t, err)
+}
+
+func TestMarshalWriteRequest(t *testing.T) {
+	raw := marshalWriteRequest([]Sample{
+		{Labels: map[string]string{LabelForName: "up"}, Timestamp: time.UnixMilli(1000), Value: 1},
+	})
+	expected := []byte{0x0a, 0x1e, // WriteRequest.timeseries
+		0x0a, 0x0e, // TimeSeries.labels
+		0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_',
+		0x12, 0x02, 'u', 'p',
+		0x12, 0x0c, // TimeSeries.samples
+		0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x3f,
+		0x10, 0xe8, 0x07,
+	}
+	assert.Equal(t, expected, raw)
+}
+
+func TestRemoteWriteAgainstHttpMock(t *testing.T) {
+	var received []byte
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultRemoteWriteEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Content-Encoding") != "snappy" ||
+				req.Header.Get("Content-Type") != "application/x-protobuf" ||
+				req.URL.Query().Get("extra_label") != "unit=test" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "wrong request"), nil
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			received, err = snappy.Decode(nil, body)
+			if err != nil {
+				return httpmock.NewStringResponse(http.StatusBadRequest, err.Error()), nil
+			}
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	client, err := New(t.Context(), Config{
+		Address:     DefaultEndpoint,
+		ExtraLabels: `unit="test"`,
+		HttpClient:  &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	samples := []Sample{
+		{Labels: map[string]string{LabelForName: "something", "job": "vmclient"}, Timestamp: time.Now().Add(-time.Hour), Value: 1},
+		{Labels: map[string]string{LabelForName: "something", "job": "vmclient"}, Timestamp: time.Now(), Value: 2},
+	}
+	err = client.RemoteWrite(t.Context(), samples)
+	assert.NoError(t, err)
+	assert.Equal(t, marshalWriteRequest(samples), received)
+}
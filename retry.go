@@ -0,0 +1,156 @@
+package vmclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy defines how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is maximal number of attempts including first one, zero or one disables retries
+	MaxAttempts int
+	// InitialBackoff is delay before second attempt, DefaultRetryInitialBackoff is used if zero.
+	// Delay is doubled for every next attempt and randomized by jitter.
+	InitialBackoff time.Duration
+	// MaxBackoff limits delay between attempts, DefaultRetryMaxBackoff is used if zero
+	MaxBackoff time.Duration
+	// RetryableStatusCodes are response status codes, which are retried, DefaultRetryableStatusCodes are used if empty.
+	// Requests failed due to network errors are always retried.
+	RetryableStatusCodes []int
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if len(p.RetryableStatusCodes) == 0 {
+		p.RetryableStatusCodes = DefaultRetryableStatusCodes
+	}
+	return p
+}
+
+// backoff returns delay before attempt with jitter, so clients do not retry simultaneously
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff << (attempt - 2)
+	if delay > p.MaxBackoff || delay <= 0 {
+		delay = p.MaxBackoff
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryAfter parses Retry-After header, which can be number of seconds or HTTP date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	when, err := http.ParseTime(value)
+	if err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+// send performs request, retrying it according to RetryPolicy. Requests with body, that cannot be read again,
+// are not retried. When attempts are exhausted, last response is returned, so it is processed as usual.
+func (c *Client) send(operation string, req *http.Request) (resp *http.Response, err error) {
+	ctx := req.Context()
+	span := trace.SpanFromContext(ctx)
+	policy := c.retry
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(ctx)
+			if req.Body != nil && req.Body != http.NoBody {
+				attemptReq.Body, err = req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if c.auth != nil {
+			// credentials are set for every attempt, since token can expire between them
+			err = c.auth.Authorize(ctx, attemptReq)
+			if err != nil {
+				return nil, fmt.Errorf("error authorizing request: %w", err)
+			}
+		}
+		c.selfMetrics.sending(operation, attemptReq)
+		started := time.Now()
+		resp, err = c.hclient.Do(attemptReq)
+		c.selfMetrics.sent(operation, started, resp, err)
+		err = c.redaction.redactError(err)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized {
+			if invalidator, ok := c.auth.(AuthInvalidator); ok {
+				invalidator.Invalidate(attemptReq)
+			}
+		}
+		var delay time.Duration
+		if err != nil {
+			span.AddEvent("attempt failed", trace.WithAttributes(
+				attribute.Int("attempt", attempt),
+				attribute.String("error", err.Error()),
+			))
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+		} else {
+			span.AddEvent("attempt performed", trace.WithAttributes(
+				attribute.Int("attempt", attempt),
+				attribute.Int("status_code", resp.StatusCode),
+			))
+			if !slices.Contains(policy.RetryableStatusCodes, resp.StatusCode) {
+				return resp, nil
+			}
+			delay = retryAfter(resp)
+		}
+		if attempt >= policy.MaxAttempts || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, err
+		}
+		if delay <= 0 {
+			delay = policy.backoff(attempt + 1)
+		}
+		deadline, present := ctx.Deadline()
+		if present && time.Now().Add(delay).After(deadline) {
+			// next attempt cannot be finished in time, so result of this one is returned
+			return resp, err
+		}
+		if resp != nil {
+			// body is drained, so connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		span.AddEvent("waiting before retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.String("backoff", delay.String()),
+		))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		c.selfMetrics.retried(operation)
+	}
+}
@@ -0,0 +1,81 @@
+package vmclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	assert.Equal(t, time.Duration(0), retryAfter(resp))
+	resp.Header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfter(resp))
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.InDelta(t, float64(time.Minute), float64(retryAfter(resp)), float64(2*time.Second))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(2)
+		assert.True(t, delay >= 50*time.Millisecond && delay <= 100*time.Millisecond, delay)
+		delay = policy.backoff(10)
+		assert.True(t, delay >= 500*time.Millisecond && delay <= time.Second, delay)
+	}
+}
+
+func TestRetryAgainstHttpMock(tt *testing.T) {
+	var failures atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+"/prometheus/api/v1/query",
+		func(req *http.Request) (*http.Response, error) {
+			if failures.Add(-1) >= 0 {
+				resp := httpmock.NewStringResponse(http.StatusServiceUnavailable, "try later")
+				resp.Header.Set("Retry-After", "0")
+				return resp, nil
+			}
+			err := req.ParseForm()
+			if err != nil || req.PostForm.Get("query") != "time()" {
+				return httpmock.NewStringResponse(http.StatusBadRequest, "body is lost on retry"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"scalar","result":[1734677495.161,"1"]}}`), nil
+		})
+
+	client, err := New(tt.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+		ForcePost:  true,
+		Retry:      RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		tt.Errorf("error creating client: %s", err)
+		return
+	}
+
+	tt.Run("recovered", func(t *testing.T) {
+		failures.Store(2)
+		result, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		if assert.NoError(t, errQ) {
+			assert.Equal(t, float64(1), result.Scalar.Value)
+		}
+	})
+
+	tt.Run("exhausted", func(t *testing.T) {
+		failures.Store(3)
+		_, errQ := client.Query(t.Context(), "time()", time.Now(), DefaultStep)
+		assert.ErrorIs(t, errQ, ErrUnexpectedResponse)
+		var properOne Err
+		if assert.ErrorAs(t, errQ, &properOne) {
+			assert.Equal(t, http.StatusServiceUnavailable, properOne.Code)
+			assert.Equal(t, "try later", properOne.Response)
+		}
+	})
+}
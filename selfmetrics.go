@@ -0,0 +1,134 @@
+package vmclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+// selfMetrics reports how client performs requests in metrics.Set provided by Config.Metrics
+type selfMetrics struct {
+	set *metrics.Set
+	// gauges are names of gauges of queues registered by client, which are unregistered when it is closed
+	gauges []string
+}
+
+const (
+	batchQueueGauge = "vmclient_batch_queue_samples"
+	diskQueueGauge  = "vmclient_disk_queue_requests"
+)
+
+func newSelfMetrics(set *metrics.Set) *selfMetrics {
+	if set == nil {
+		return nil
+	}
+	return &selfMetrics{set: set}
+}
+
+// checkQueues returns error, if gauges of queues to be registered are already registered by other client
+// in the same set, since gauge would keep reporting queues of that client
+func (m *selfMetrics) checkQueues(batching, queueing bool) error {
+	if m == nil {
+		return nil
+	}
+	registered := m.set.ListMetricNames()
+	for name, used := range map[string]bool{batchQueueGauge: batching, diskQueueGauge: queueing} {
+		if used && slices.Contains(registered, name) {
+			return fmt.Errorf("metric %s is already registered by other client, close it or use other metrics set", name)
+		}
+	}
+	return nil
+}
+
+// registerQueues registers gauges of number of samples waiting in batching queue and requests waiting in disk queue
+func (m *selfMetrics) registerQueues(b *batcher, q *diskQueue) {
+	if m == nil {
+		return
+	}
+	if b != nil {
+		m.set.GetOrCreateGauge(batchQueueGauge, func() float64 {
+			return float64(len(b.queue))
+		})
+		m.gauges = append(m.gauges, batchQueueGauge)
+	}
+	if q != nil {
+		m.set.GetOrCreateGauge(diskQueueGauge, func() float64 {
+			return float64(q.len())
+		})
+		m.gauges = append(m.gauges, diskQueueGauge)
+	}
+}
+
+// unregisterQueues removes gauges of queues from set, so it can be used by other client
+func (m *selfMetrics) unregisterQueues() {
+	if m == nil {
+		return
+	}
+	for _, name := range m.gauges {
+		m.set.UnregisterMetric(name)
+	}
+	m.gauges = nil
+}
+
+// sending counts bytes of body of request, while it is being sent
+func (m *selfMetrics) sending(operation string, req *http.Request) {
+	if m == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	req.Body = &meteredBody{
+		ReadCloser: req.Body,
+		counter:    m.set.GetOrCreateCounter(fmt.Sprintf(`vmclient_request_bytes_total{operation=%q}`, operation)),
+	}
+}
+
+// sent counts request attempt and records its duration by status code, counting bytes of response body,
+// while it is being read. Requests failed without response have status "error".
+func (m *selfMetrics) sent(operation string, started time.Time, resp *http.Response, err error) {
+	if m == nil {
+		return
+	}
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+		resp.Body = &meteredBody{
+			ReadCloser: resp.Body,
+			counter:    m.set.GetOrCreateCounter(fmt.Sprintf(`vmclient_response_bytes_total{operation=%q}`, operation)),
+		}
+	}
+	m.set.GetOrCreateCounter(fmt.Sprintf(`vmclient_requests_total{operation=%q,status=%q}`, operation, status)).Inc()
+	m.set.GetOrCreateHistogram(fmt.Sprintf(`vmclient_request_duration_seconds{operation=%q,status=%q}`,
+		operation, status)).UpdateDuration(started)
+}
+
+// retried counts attempts made again after failed ones
+func (m *selfMetrics) retried(operation string) {
+	if m == nil {
+		return
+	}
+	m.set.GetOrCreateCounter(fmt.Sprintf(`vmclient_retries_total{operation=%q}`, operation)).Inc()
+}
+
+// flushed records number of samples in batch sent by batcher
+func (m *selfMetrics) flushed(samples int) {
+	if m == nil {
+		return
+	}
+	m.set.GetOrCreateHistogram("vmclient_push_batch_samples").Update(float64(samples))
+}
+
+// meteredBody adds number of bytes read to counter
+type meteredBody struct {
+	io.ReadCloser
+	counter *metrics.Counter
+}
+
+func (b *meteredBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.counter.Add(n)
+	return n, err
+}
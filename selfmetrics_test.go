@@ -0,0 +1,119 @@
+package vmclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfMetricsAgainstHttpMock(t *testing.T) {
+	var writes atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query_range`,
+		httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1734677495,"1"],[1734677555,"1"]]}]}}`))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultRemoteWriteEndpoint,
+		func(req *http.Request) (*http.Response, error) {
+			io.Copy(io.Discard, req.Body)
+			// first write fails, so it is retried
+			if writes.Add(1) == 1 {
+				return httpmock.NewStringResponse(http.StatusServiceUnavailable, "unavailable"), nil
+			}
+			return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+		})
+
+	set := metrics.NewSet()
+	client, err := New(t.Context(), Config{
+		Address:       DefaultEndpoint,
+		HttpClient:    &http.Client{Transport: mockTransport},
+		Metrics:       set,
+		Retry:         RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		BatchSize:     2,
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	_, err = client.Range(t.Context(), "up", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, client.EnqueueGauge(t.Context(), `something{job="a"}`, 1))
+	assert.NoError(t, client.EnqueueGauge(t.Context(), `something{job="b"}`, 2))
+	assert.Contains(t, set.ListMetricNames(), batchQueueGauge)
+	assert.NoError(t, client.Close(t.Context()))
+
+	buf := bytes.Buffer{}
+	set.WritePrometheus(&buf)
+	exposed := buf.String()
+	for _, expected := range []string{
+		`vmclient_requests_total{operation="ping",status="200"} 1`,
+		`vmclient_requests_total{operation="range",status="200"} 1`,
+		`vmclient_requests_total{operation="remote_write",status="503"} 1`,
+		`vmclient_requests_total{operation="remote_write",status="204"} 1`,
+		`vmclient_retries_total{operation="remote_write"} 1`,
+		`vmclient_request_duration_seconds_count{operation="range",status="200"} 1`,
+		`vmclient_push_batch_samples_sum 2`,
+	} {
+		assert.Contains(t, exposed, expected)
+	}
+	for _, line := range strings.Split(exposed, "\n") {
+		if strings.HasPrefix(line, `vmclient_response_bytes_total{operation="range"}`) ||
+			strings.HasPrefix(line, `vmclient_request_bytes_total{operation="remote_write"}`) {
+			assert.NotEqual(t, "0", line[strings.LastIndex(line, " ")+1:], line)
+		}
+	}
+	assert.Contains(t, exposed, `vmclient_response_bytes_total{operation="range"}`)
+	assert.Contains(t, exposed, `vmclient_request_bytes_total{operation="remote_write"}`)
+	assert.NotContains(t, exposed, batchQueueGauge, "gauge of closed client is not unregistered")
+}
+
+func TestSelfMetricsQueueGauges(t *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodPost, DefaultEndpoint+DefaultRemoteWriteEndpoint,
+		httpmock.NewStringResponder(http.StatusNoContent, ""))
+
+	set := metrics.NewSet()
+	cfg := Config{
+		Address:       DefaultEndpoint,
+		HttpClient:    &http.Client{Transport: mockTransport},
+		Metrics:       set,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	}
+	first, err := New(t.Context(), cfg)
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	t.Run("set is not shared", func(tt *testing.T) {
+		_, err = New(tt.Context(), cfg)
+		assert.ErrorContains(tt, err, batchQueueGauge)
+	})
+	assert.NoError(t, first.Close(t.Context()))
+	assert.NotContains(t, set.ListMetricNames(), batchQueueGauge)
+
+	t.Run("set is reused after close", func(tt *testing.T) {
+		second, err := New(tt.Context(), cfg)
+		if err != nil {
+			tt.Errorf("error creating client: %s", err)
+			return
+		}
+		defer second.Close(tt.Context())
+		assert.NoError(tt, second.EnqueueGauge(tt.Context(), `something{job="a"}`, 1))
+		buf := bytes.Buffer{}
+		set.WritePrometheus(&buf)
+		assert.Contains(tt, buf.String(), batchQueueGauge+" 1")
+	})
+}
@@ -0,0 +1,143 @@
+package vmclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Series is time series found by series discovery API, described only by its labels
+type Series struct {
+	Labels map[string]string
+}
+
+func (s *Series) Name() string {
+	name, found := s.Labels[LabelForName]
+	if found {
+		return name
+	}
+	return ""
+}
+
+func (s *Series) String() string {
+	return labelsToString(s.Labels)
+}
+
+type seriesRawResponse struct {
+	Status string              `json:"status"`
+	Data   []map[string]string `json:"data"`
+}
+
+type labelsRawResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+}
+
+// Series returns time series matching any of match[] selectors on time range between start and end,
+// as described here https://docs.victoriametrics.com/victoriametrics/url-examples/#apiv1series
+// Zero start or end are not sent to database, so its defaults are used.
+func (c *Client) Series(initialCtx context.Context, match []string, start, end time.Time) (data []Series, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "series",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "series", c.endpoint, time.Now(), &err)
+
+	resp, err := c.do(ctx, "series", doParams{match: match, start: start, end: end})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	err = handleErrorResponse(resp, span)
+	if err != nil {
+		return nil, err
+	}
+	span.AddEvent("request performed")
+	var raw seriesRawResponse
+	err = json.NewDecoder(resp.Body).Decode(&raw)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.AddEvent("body parsed")
+	if raw.Status != "success" {
+		err = fmt.Errorf("wrong status: %s", raw.Status)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	data = make([]Series, len(raw.Data))
+	for i := range raw.Data {
+		data[i] = Series{Labels: raw.Data[i]}
+	}
+	c.telemetry.returned(ctx, "series", c.endpoint, len(data))
+	span.SetStatus(codes.Ok, "data received")
+	return data, nil
+}
+
+// LabelNames returns names of labels of time series matching any of match[] selectors on time range between start and end,
+// as described here https://docs.victoriametrics.com/victoriametrics/url-examples/#apiv1labels
+func (c *Client) LabelNames(initialCtx context.Context, match []string, start, end time.Time) (data []string, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "labels",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "labels", c.endpoint, time.Now(), &err)
+
+	return c.labels(ctx, span, "labels", doParams{match: match, start: start, end: end})
+}
+
+// LabelValues returns values of label with name provided for time series matching any of match[] selectors
+// on time range between start and end, as described here
+// https://docs.victoriametrics.com/victoriametrics/url-examples/#apiv1labelvalues
+func (c *Client) LabelValues(initialCtx context.Context, name string, match []string, start, end time.Time) (data []string, err error) {
+	ctx, span := c.tracer.Start(initialCtx, "label_values",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+			semconv.DBSystemNameKey.String("Victoria Metrics")),
+	)
+	defer span.End()
+	defer c.telemetry.record(ctx, "label_values", c.endpoint, time.Now(), &err)
+
+	return c.labels(ctx, span, "label_values", doParams{label: name, match: match, start: start, end: end})
+}
+
+func (c *Client) labels(ctx context.Context, span trace.Span, operation string, params doParams) (data []string, err error) {
+	resp, err := c.do(ctx, operation, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	err = handleErrorResponse(resp, span)
+	if err != nil {
+		return nil, err
+	}
+	span.AddEvent("request performed")
+	var raw labelsRawResponse
+	err = json.NewDecoder(resp.Body).Decode(&raw)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.AddEvent("body parsed")
+	if raw.Status != "success" {
+		err = fmt.Errorf("wrong status: %s", raw.Status)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	c.telemetry.returned(ctx, operation, c.endpoint, len(raw.Data))
+	span.SetStatus(codes.Ok, "data received")
+	return raw.Data, nil
+}
@@ -0,0 +1,108 @@
+package vmclient
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesAgainstHttpMock(tt *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	matcher := httpmock.NewMatcher("validateMatch", func(req *http.Request) bool {
+		return req.URL.Query().Get("match[]") == `something{job="vmclient"}`
+	})
+	seriesResponder, err := httpmock.NewJsonResponder(http.StatusOK, seriesRawResponse{
+		Status: "success",
+		Data: []map[string]string{
+			{"__name__": "something", "job": "vmclient", "unit": "test"},
+			{"__name__": "something", "job": "vmclient", "unit": "prod"},
+		},
+	})
+	if err != nil {
+		tt.Fatal(err)
+	}
+	mockTransport.RegisterMatcherResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/series",
+		matcher, seriesResponder)
+	mockTransport.RegisterMatcherResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/labels",
+		matcher, httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":["__name__","job","unit"]}`))
+	mockTransport.RegisterMatcherResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/label/unit/values",
+		matcher, httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":["prod","test"]}`))
+
+	transport := &closeTracking{RoundTripper: mockTransport}
+	client, errC := New(tt.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: transport},
+	})
+	if errC != nil {
+		tt.Errorf("error creating client: %s", errC)
+		return
+	}
+	match := []string{`something{job="vmclient"}`}
+
+	tt.Run("series", func(t *testing.T) {
+		series, errS := client.Series(t.Context(), match, time.Now().Add(-time.Hour), time.Now())
+		if errS != nil {
+			t.Errorf("error listing series: %s", errS)
+			return
+		}
+		assert.Len(t, series, 2)
+		for i := range series {
+			assert.Equal(t, "something", series[i].Name())
+			assert.Equal(t, "vmclient", series[i].Labels["job"])
+		}
+	})
+
+	tt.Run("label names", func(t *testing.T) {
+		names, errL := client.LabelNames(t.Context(), match, time.Time{}, time.Time{})
+		if errL != nil {
+			t.Errorf("error listing label names: %s", errL)
+			return
+		}
+		assert.Equal(t, []string{"__name__", "job", "unit"}, names)
+	})
+
+	tt.Run("label values", func(t *testing.T) {
+		values, errL := client.LabelValues(t.Context(), "unit", match, time.Time{}, time.Time{})
+		if errL != nil {
+			t.Errorf("error listing label values: %s", errL)
+			return
+		}
+		assert.Equal(t, []string{"prod", "test"}, values)
+	})
+
+	assert.Equal(tt, transport.opened.Load(), transport.closed.Load(), "response bodies are not closed")
+}
+
+// closeTracking counts response bodies received and closed
+type closeTracking struct {
+	http.RoundTripper
+	opened atomic.Int32
+	closed atomic.Int32
+}
+
+func (t *closeTracking) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.opened.Add(1)
+	resp.Body = trackedBody{ReadCloser: resp.Body, closed: &t.closed}
+	return resp, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	closed *atomic.Int32
+}
+
+func (b trackedBody) Close() error {
+	b.closed.Add(1)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,136 @@
+package vmclient
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type window struct {
+	start time.Time
+	end   time.Time
+}
+
+// splitWindow cuts time range into chunks containing no more than maxPoints steps each.
+// Chunk boundaries are aligned to step starting from start, so every evaluation point belongs to exactly one chunk.
+func splitWindow(start, end time.Time, step time.Duration, maxPoints int) (chunks []window) {
+	if maxPoints < 1 {
+		maxPoints = 1
+	}
+	chunk := step * time.Duration(maxPoints-1)
+	from := start
+	for !from.After(end) {
+		to := from.Add(chunk)
+		if to.After(end) {
+			to = end
+		}
+		chunks = append(chunks, window{start: from, end: to})
+		from = to.Add(step)
+	}
+	return chunks
+}
+
+// mergeRanges merges time series from chunks by labels, sorting values by timestamp and removing duplicates
+// happening on chunk boundaries.
+func mergeRanges(chunks [][]Range) (data []Range) {
+	index := make(map[string]int)
+	for i := range chunks {
+		for j := range chunks[i] {
+			key := labelsToString(chunks[i][j].Labels)
+			k, found := index[key]
+			if !found {
+				index[key] = len(data)
+				data = append(data, Range{
+					Labels: chunks[i][j].Labels,
+					Values: append([]Result(nil), chunks[i][j].Values...),
+				})
+				continue
+			}
+			data[k].Values = append(data[k].Values, chunks[i][j].Values...)
+		}
+	}
+	for i := range data {
+		values := data[i].Values
+		sort.SliceStable(values, func(a, b int) bool {
+			return values[a].Timestamp.Before(values[b].Timestamp)
+		})
+		deduplicated := values[:0]
+		for j := range values {
+			if len(deduplicated) > 0 && deduplicated[len(deduplicated)-1].Timestamp.Equal(values[j].Timestamp) {
+				continue
+			}
+			deduplicated = append(deduplicated, values[j])
+		}
+		data[i].Values = deduplicated
+	}
+	return data
+}
+
+func (c *Client) rangeSplit(initialCtx context.Context, span trace.Span, params doParams) (data []Range, err error) {
+	chunks := splitWindow(params.start, params.end, params.step, c.maxPointsPerRequest)
+	concurrency := c.splitConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultSplitConcurrency
+	}
+	span.SetAttributes(attribute.Int("split.chunks", len(chunks)),
+		attribute.Int("split.concurrency", concurrency),
+	)
+	span.AddEvent("range split into chunks")
+
+	ctx, cancel := context.WithCancel(initialCtx)
+	defer cancel()
+
+	results := make([][]Range, len(chunks))
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	once := sync.Once{}
+	for i := range chunks {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			chunkCtx, chunkSpan := c.tracer.Start(ctx, "range_chunk",
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.Int("split.chunk", i)),
+			)
+			defer chunkSpan.End()
+			chunkParams := params
+			chunkParams.start = chunks[i].start
+			chunkParams.end = chunks[i].end
+			chunkData, chunkErr := c.rangeQuery(chunkCtx, chunkSpan, chunkParams)
+			if chunkErr != nil {
+				// error of chunk failed first is more meaningful, than cancellation errors of others
+				once.Do(func() {
+					err = chunkErr
+					cancel()
+				})
+				return
+			}
+			results[i] = chunkData
+			chunkSpan.SetStatus(codes.Ok, "data received")
+		}(i)
+	}
+	wg.Wait()
+	if err == nil && initialCtx.Err() != nil {
+		err = initialCtx.Err()
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+	span.AddEvent("chunks received")
+	return mergeRanges(results), nil
+}
@@ -0,0 +1,91 @@
+package vmclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWindow(t *testing.T) {
+	start := time.Unix(1734677400, 0)
+	chunks := splitWindow(start, start.Add(10*time.Minute), time.Minute, 4)
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, start, chunks[0].start)
+	assert.Equal(t, start.Add(3*time.Minute), chunks[0].end)
+	assert.Equal(t, start.Add(4*time.Minute), chunks[1].start)
+	assert.Equal(t, start.Add(7*time.Minute), chunks[1].end)
+	assert.Equal(t, start.Add(8*time.Minute), chunks[2].start)
+	assert.Equal(t, start.Add(10*time.Minute), chunks[2].end)
+}
+
+func TestMergeRanges(t *testing.T) {
+	ts := time.Unix(1734677400, 0)
+	merged := mergeRanges([][]Range{
+		{
+			{Labels: map[string]string{"job": "a"}, Values: []Result{{Value: 1, Timestamp: ts}, {Value: 2, Timestamp: ts.Add(time.Minute)}}},
+		},
+		{
+			{Labels: map[string]string{"job": "b"}, Values: []Result{{Value: 10, Timestamp: ts.Add(time.Minute)}}},
+			{Labels: map[string]string{"job": "a"}, Values: []Result{{Value: 2, Timestamp: ts.Add(time.Minute)}, {Value: 3, Timestamp: ts.Add(2 * time.Minute)}}},
+		},
+	})
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "a", merged[0].Labels["job"])
+	assert.Equal(t, []Result{
+		{Value: 1, Timestamp: ts},
+		{Value: 2, Timestamp: ts.Add(time.Minute)},
+		{Value: 3, Timestamp: ts.Add(2 * time.Minute)},
+	}, merged[0].Values)
+	assert.Equal(t, "b", merged[1].Labels["job"])
+	assert.Len(t, merged[1].Values, 1)
+}
+
+func TestRangeSplitAgainstHttpMock(t *testing.T) {
+	var requests atomic.Int32
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "200th status code is enough to trick vm client"))
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/prometheus/api/v1/query_range",
+		func(req *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			start, _ := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+			end, _ := strconv.ParseInt(req.URL.Query().Get("end"), 10, 64)
+			var values []string
+			for ts := start; ts <= end; ts += 60 {
+				values = append(values, fmt.Sprintf("[%v,\"%v\"]", ts, ts))
+			}
+			return httpmock.NewStringResponse(http.StatusOK,
+				`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"something"},"values":[`+
+					strings.Join(values, ",")+`]}]}}`), nil
+		})
+
+	client, errC := New(t.Context(), Config{
+		Address:             DefaultEndpoint,
+		HttpClient:          &http.Client{Transport: mockTransport},
+		MaxPointsPerRequest: 10,
+		SplitConcurrency:    2,
+	})
+	if errC != nil {
+		t.Errorf("error creating client: %s", errC)
+		return
+	}
+	start := time.Unix(1734677400, 0)
+	lines, err := client.Range(t.Context(), "something", start, start.Add(99*time.Minute), time.Minute)
+	if err != nil {
+		t.Errorf("error sending range query: %s", err)
+		return
+	}
+	assert.Equal(t, int32(10), requests.Load())
+	assert.Len(t, lines, 1)
+	assert.Len(t, lines[0].Values, 100)
+	for i := range lines[0].Values {
+		assert.Equal(t, start.Add(time.Duration(i)*time.Minute), lines[0].Values[i].Timestamp)
+	}
+}
@@ -0,0 +1,340 @@
+package vmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// parseTimestamp parses unix timestamp in seconds with fractional part, like 1734677495.161, without
+// rounding errors of float64
+func parseTimestamp(raw []byte) (time.Time, error) {
+	seconds, fraction, _ := bytes.Cut(raw, []byte("."))
+	if bytes.ContainsAny(raw, "eE") {
+		parsed, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing timestamp %s: %w", raw, err)
+		}
+		return time.UnixMilli(int64(1000 * parsed)), nil
+	}
+	sec, err := strconv.ParseInt(string(seconds), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing timestamp %s: %w", raw, err)
+	}
+	var ms int64
+	for i := 0; i < 3; i++ {
+		ms *= 10
+		if i < len(fraction) {
+			if fraction[i] < '0' || fraction[i] > '9' {
+				return time.Time{}, fmt.Errorf("error parsing timestamp %s", raw)
+			}
+			ms += int64(fraction[i] - '0')
+		}
+	}
+	if len(seconds) > 0 && seconds[0] == '-' {
+		ms = -ms
+	}
+	return time.UnixMilli(sec*1000 + ms), nil
+}
+
+func skipSpaces(data []byte) []byte {
+	for len(data) > 0 && (data[0] == ' ' || data[0] == '\t' || data[0] == '\n' || data[0] == '\r') {
+		data = data[1:]
+	}
+	return data
+}
+
+// parsePair parses [ts, "value"] pair at the beginning of data and returns rest of data
+func parsePair(data []byte) (pair Result, rest []byte, err error) {
+	data = skipSpaces(data)
+	if len(data) == 0 || data[0] != '[' {
+		return pair, nil, fmt.Errorf("pair of timestamp and value is expected at %.32q", data)
+	}
+	data = skipSpaces(data[1:])
+	end := bytes.IndexByte(data, ',')
+	if end < 0 {
+		return pair, nil, fmt.Errorf("value is expected at %.32q", data)
+	}
+	pair.Timestamp, err = parseTimestamp(bytes.TrimSpace(data[:end]))
+	if err != nil {
+		return pair, nil, err
+	}
+	data = skipSpaces(data[end+1:])
+	if len(data) == 0 || data[0] != '"' {
+		return pair, nil, fmt.Errorf("quoted value is expected at %.32q", data)
+	}
+	end = bytes.IndexByte(data[1:], '"')
+	if end < 0 {
+		return pair, nil, fmt.Errorf("unterminated value at %.32q", data)
+	}
+	raw := data[1 : end+1]
+	pair.Value, err = strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return pair, nil, fmt.Errorf("error parsing value %s: %w", raw, err)
+	}
+	data = skipSpaces(data[end+2:])
+	if len(data) == 0 || data[0] != ']' {
+		return pair, nil, fmt.Errorf("end of pair is expected at %.32q", data)
+	}
+	return pair, data[1:], nil
+}
+
+// samplePair is [ts, "value"] pair of instant vector, parsed without interface boxing
+type samplePair Result
+
+func (p *samplePair) UnmarshalJSON(data []byte) error {
+	pair, _, err := parsePair(data)
+	if err != nil {
+		return err
+	}
+	*p = samplePair(pair)
+	return nil
+}
+
+// samplePairs is list of [ts, "value"] pairs of range vector, parsed without interface boxing
+type samplePairs []Result
+
+func (p *samplePairs) UnmarshalJSON(data []byte) error {
+	data = skipSpaces(data)
+	if len(data) == 0 || data[0] != '[' {
+		return fmt.Errorf("list of values is expected at %.32q", data)
+	}
+	data = skipSpaces(data[1:])
+	pairs := make(samplePairs, 0, bytes.Count(data, []byte("]")))
+	for len(data) > 0 && data[0] != ']' {
+		pair, rest, err := parsePair(data)
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, pair)
+		data = skipSpaces(rest)
+		if len(data) > 0 && data[0] == ',' {
+			data = skipSpaces(data[1:])
+		}
+	}
+	*p = pairs
+	return nil
+}
+
+// streamSeries is element of result of instant or range query
+type streamSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values samplePairs       `json:"values"`
+	Value  *samplePair       `json:"value"`
+}
+
+func (s *streamSeries) convert() Range {
+	output := Range{Labels: s.Metric, Values: []Result(s.Values)}
+	if output.Values == nil && s.Value != nil {
+		output.Values = []Result{Result(*s.Value)}
+	}
+	return output
+}
+
+// expectDelim reads next token of decoder and checks if it is expected delimiter
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("%w: %v is expected instead of %v", ErrUnexpectedResponse, expected, token)
+	}
+	return nil
+}
+
+// streamDecoder decodes response of query API, calling yield for series one by one, until it returns false,
+// and aborting decoding, when response limits are exceeded
+type streamDecoder struct {
+	decoder *json.Decoder
+	counter *responseCounter
+	yield   func(Range) bool
+	stopped bool
+	// anyType makes scalar and string results to be accepted, they are kept in other as is
+	anyType    bool
+	resultType ResultType
+	other      json.RawMessage
+}
+
+func newStreamDecoder(body io.Reader, limits responseLimits, yield func(Range) bool) *streamDecoder {
+	counter := &responseCounter{limits: limits}
+	return &streamDecoder{decoder: json.NewDecoder(counter.reader(body)), counter: counter, yield: yield}
+}
+
+// decode decodes response object
+func (d *streamDecoder) decode() (err error) {
+	err = expectDelim(d.decoder, '{')
+	if err != nil {
+		return err
+	}
+	for !d.stopped && d.decoder.More() {
+		var key string
+		err = d.decoder.Decode(&key)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "status":
+			var status string
+			err = d.decoder.Decode(&status)
+			if err == nil && status != "success" {
+				err = fmt.Errorf("wrong status: %s", status)
+			}
+		case "data":
+			err = d.decodeData()
+		default:
+			var skipped json.RawMessage
+			err = d.decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeData decodes data object of response
+func (d *streamDecoder) decodeData() (err error) {
+	err = expectDelim(d.decoder, '{')
+	if err != nil {
+		return err
+	}
+	for d.decoder.More() {
+		var key string
+		err = d.decoder.Decode(&key)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "resultType":
+			err = d.decoder.Decode(&d.resultType)
+			if err == nil && !d.anyType && d.resultType != ResultTypeVector && d.resultType != ResultTypeMatrix {
+				err = fmt.Errorf("%w: %s", ErrUnexpectedResultType, d.resultType)
+			}
+		case "result":
+			if d.resultType == ResultTypeScalar || d.resultType == ResultTypeString {
+				err = d.decoder.Decode(&d.other)
+				break
+			}
+			err = d.decodeResult()
+			if d.stopped {
+				return err
+			}
+		default:
+			var skipped json.RawMessage
+			err = d.decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(d.decoder, '}')
+}
+
+// decodeResult decodes list of series
+func (d *streamDecoder) decodeResult() (err error) {
+	err = expectDelim(d.decoder, '[')
+	if err != nil {
+		return err
+	}
+	for d.decoder.More() {
+		var element streamSeries
+		err = d.decoder.Decode(&element)
+		if err != nil {
+			return err
+		}
+		output := element.convert()
+		err = d.counter.add(len(output.Values))
+		if err != nil {
+			return err
+		}
+		if !d.yield(output) {
+			d.stopped = true
+			return nil
+		}
+	}
+	return expectDelim(d.decoder, ']')
+}
+
+// streamQuery performs query and yields series of response one by one
+func (c *Client) streamQuery(ctx context.Context, span trace.Span, operation string, params doParams,
+	yield func(Range, error) bool) (err error) {
+	resp, err := c.do(ctx, operation, params)
+	if err != nil {
+		yield(Range{}, err)
+		return err
+	}
+	defer resp.Body.Close()
+	err = handleErrorResponse(resp, span)
+	if err != nil {
+		yield(Range{}, err)
+		return err
+	}
+	span.AddEvent("request performed")
+	decoder := newStreamDecoder(resp.Body, c.responseLimits, func(output Range) bool {
+		return yield(output, nil)
+	})
+	err = decoder.decode()
+	span.SetAttributes(attribute.Int("series", decoder.counter.series))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		yield(Range{}, err)
+		return err
+	}
+	c.telemetry.returned(ctx, operation, c.endpoint, decoder.counter.series)
+	if decoder.stopped {
+		span.SetStatus(codes.Ok, "query interrupted")
+		return nil
+	}
+	span.SetStatus(codes.Ok, "data received")
+	return nil
+}
+
+// RangeStream performs range query like Range, but decodes series of response one by one while response
+// body is being read, so memory used does not depend on number of series returned. Iteration stops on first error.
+func (c *Client) RangeStream(initialCtx context.Context, query string, start, end time.Time, step time.Duration,
+	opts ...QueryOption) iter.Seq2[Range, error] {
+	return func(yield func(Range, error) bool) {
+		ctx, span := c.tracer.Start(initialCtx, "range",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+				semconv.DBSystemNameKey.String("Victoria Metrics")),
+		)
+		defer span.End()
+		var err error
+		defer c.telemetry.record(ctx, "range", c.endpoint, time.Now(), &err)
+
+		err = c.streamQuery(ctx, span, "range", doParams{query: query, start: start, end: end, step: step,
+			opts: newQueryOptions(opts)}, yield)
+	}
+}
+
+// InstantStream performs instant query like Instant, but decodes series of response one by one while response
+// body is being read. Every series yielded has single value. Iteration stops on first error.
+func (c *Client) InstantStream(initialCtx context.Context, query string, when time.Time, step time.Duration,
+	opts ...QueryOption) iter.Seq2[Range, error] {
+	return func(yield func(Range, error) bool) {
+		ctx, span := c.tracer.Start(initialCtx, "instant",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBClientConnectionPoolName(c.endpoint),
+				semconv.DBSystemNameKey.String("Victoria Metrics")),
+		)
+		defer span.End()
+		var err error
+		defer c.telemetry.record(ctx, "instant", c.endpoint, time.Now(), &err)
+
+		err = c.streamQuery(ctx, span, "instant", doParams{query: query, when: when, step: step,
+			opts: newQueryOptions(opts)}, yield)
+	}
+}
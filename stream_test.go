@@ -0,0 +1,104 @@
+package vmclient
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	for raw, expected := range map[string]int64{
+		"1734677495.161": 1734677495161,
+		"1734677495.1":   1734677495100,
+		"1734677495":     1734677495000,
+		"1.734677495e9":  1734677495000,
+	} {
+		parsed, err := parseTimestamp([]byte(raw))
+		if assert.NoError(t, err, raw) {
+			assert.Equal(t, expected, parsed.UnixMilli(), raw)
+		}
+	}
+	_, err := parseTimestamp([]byte("tomorrow"))
+	assert.Error(t, err)
+}
+
+func TestStreamDecoder(t *testing.T) {
+	body := `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"first"},"values":[[1734677495.161,"1"], [1734677555.161, "NaN"]]},
+		{"metric":{"__name__":"second"},"values":[[1734677495.161,"+Inf"]]}
+	]},"stats":{"seriesFetched":"2"}}`
+	var decoded []Range
+	decoder := newStreamDecoder(strings.NewReader(body), responseLimits{}, func(output Range) bool {
+		decoded = append(decoded, output)
+		return true
+	})
+	if !assert.NoError(t, decoder.decode()) {
+		return
+	}
+	assert.Equal(t, 2, decoder.counter.series)
+	if assert.Len(t, decoded, 2) {
+		assert.Equal(t, "first", decoded[0].Name())
+		assert.Equal(t, time.UnixMilli(1734677555161), decoded[0].Values[1].Timestamp)
+		assert.True(t, math.IsNaN(decoded[0].Values[1].Value))
+		assert.True(t, math.IsInf(decoded[1].Values[0].Value, 1))
+	}
+
+	decoded = nil
+	decoder = newStreamDecoder(strings.NewReader(body), responseLimits{}, func(output Range) bool {
+		decoded = append(decoded, output)
+		return false
+	})
+	assert.NoError(t, decoder.decode())
+	assert.True(t, decoder.stopped)
+	assert.Len(t, decoded, 1)
+
+	decoder = newStreamDecoder(strings.NewReader(`{"status":"success","data":{"resultType":"scalar","result":[1,"1"]}}`),
+		responseLimits{},
+		func(Range) bool { return true })
+	assert.ErrorIs(t, decoder.decode(), ErrUnexpectedResultType)
+
+	decoder = newStreamDecoder(strings.NewReader(`{"status":"success","data":{"result":[{"metric":{},"values":[[1,1]]}]}}`),
+		responseLimits{},
+		func(Range) bool { return true })
+	assert.Error(t, decoder.decode(), "unquoted value is parsed")
+}
+
+func TestStreamAgainstHttpMock(t *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query\?`,
+		httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"up","job":"a"},"value":[1734677495.161,"1"]},
+			{"metric":{"__name__":"up","job":"b"},"value":[1734677495.161,"0"]}]}}`))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query_range`,
+		httpmock.NewStringResponder(http.StatusUnprocessableEntity,
+			`{"status":"error","errorType":"422","error":"cannot parse query"}`))
+
+	client, err := New(t.Context(), Config{
+		Address:    DefaultEndpoint,
+		HttpClient: &http.Client{Transport: mockTransport},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	var jobs []string
+	for series, errS := range client.InstantStream(t.Context(), "up", time.Now(), DefaultStep) {
+		if !assert.NoError(t, errS) {
+			break
+		}
+		assert.Len(t, series.Values, 1)
+		jobs = append(jobs, series.Labels["job"])
+	}
+	assert.Equal(t, []string{"a", "b"}, jobs)
+
+	for _, errS := range client.RangeStream(t.Context(), "up{", time.Now().Add(-time.Hour), time.Now(), time.Minute) {
+		assert.ErrorIs(t, errS, ErrQueryError)
+	}
+}
@@ -0,0 +1,140 @@
+package vmclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.38.0"
+	"go.opentelemetry.io/otel/semconv/v1.38.0/dbconv"
+	"go.opentelemetry.io/otel/semconv/v1.38.0/httpconv"
+)
+
+// instrumentationName is name of tracer and meter of client
+const instrumentationName = "vmclient"
+
+// telemetry records metrics of operations as described by semantic conventions of database clients
+// https://opentelemetry.io/docs/specs/semconv/database/database-metrics/
+type telemetry struct {
+	duration     dbconv.ClientOperationDuration
+	returnedRows dbconv.ClientResponseReturnedRows
+	responseSize httpconv.ClientResponseBodySize
+}
+
+func newTelemetry(provider metric.MeterProvider) (t *telemetry, err error) {
+	meter := provider.Meter(instrumentationName)
+	t = &telemetry{}
+	t.duration, err = dbconv.NewClientOperationDuration(meter)
+	if err != nil {
+		return nil, err
+	}
+	t.returnedRows, err = dbconv.NewClientResponseReturnedRows(meter)
+	if err != nil {
+		return nil, err
+	}
+	t.responseSize, err = httpconv.NewClientResponseBodySize(meter)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// serverAddress returns host and port of url, port is derived from scheme, if it is not set explicitly
+func serverAddress(u *url.URL) (host string, port int) {
+	port, _ = strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 80
+		if u.Scheme == "https" {
+			port = 443
+		}
+	}
+	return u.Hostname(), port
+}
+
+// serverAttributes returns server.address and server.port of database endpoint
+func serverAttributes(endpoint string) []attribute.KeyValue {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	host, port := serverAddress(u)
+	return []attribute.KeyValue{semconv.ServerAddress(host), semconv.ServerPort(port)}
+}
+
+// errorType returns low cardinality description of error recorded as error.type attribute
+func errorType(err error) string {
+	var statusErr Err
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrLimitExceeded):
+		return "limit_exceeded"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.As(err, &statusErr) && statusErr.Code != 0:
+		return strconv.Itoa(statusErr.Code)
+	default:
+		return fmt.Sprintf("%T", err)
+	}
+}
+
+// record records duration of operation started and type of error it is finished with.
+// It is meant to be deferred with pointer to named error returned by operation.
+func (t *telemetry) record(ctx context.Context, operation, endpoint string, started time.Time, err *error) {
+	attrs := append(serverAttributes(endpoint), semconv.DBOperationName(operation))
+	if *err != nil {
+		kind := errorType(*err)
+		attrs = append(attrs, semconv.ErrorTypeKey.String(kind))
+		var statusErr Err
+		if errors.As(*err, &statusErr) && statusErr.Code != 0 {
+			attrs = append(attrs, semconv.DBResponseStatusCode(strconv.Itoa(statusErr.Code)))
+		}
+	}
+	t.duration.Record(ctx, time.Since(started).Seconds(), dbconv.SystemNameAttr("Victoria Metrics"), attrs...)
+}
+
+// returned records number of series, labels or records returned by operation
+func (t *telemetry) returned(ctx context.Context, operation, endpoint string, rows int) {
+	attrs := append(serverAttributes(endpoint), semconv.DBOperationName(operation))
+	t.returnedRows.Record(ctx, int64(rows), dbconv.SystemNameAttr("Victoria Metrics"), attrs...)
+}
+
+// measureResponse replaces body of response with one, that records its size, when it is closed
+func (t *telemetry) measureResponse(ctx context.Context, operation string, req *http.Request, res *http.Response) {
+	host, port := serverAddress(req.URL)
+	res.Body = &measuredBody{ReadCloser: res.Body, record: func(size int64) {
+		t.responseSize.Record(ctx, size, httpconv.RequestMethodAttr(req.Method), host, port,
+			semconv.DBOperationName(operation), semconv.HTTPResponseStatusCode(res.StatusCode))
+	}}
+}
+
+// measuredBody counts bytes of response body read and records them once, when it is closed
+type measuredBody struct {
+	io.ReadCloser
+	n      int64
+	once   sync.Once
+	record func(size int64)
+}
+
+func (b *measuredBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *measuredBody) Close() error {
+	b.once.Do(func() {
+		b.record(b.n)
+	})
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,110 @@
+package vmclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// histogramPoint returns data point of histogram with name recorded for operation
+func histogramPoint[N int64 | float64](t *testing.T, collected metricdata.ResourceMetrics, name, operation string) (
+	point metricdata.HistogramDataPoint[N], found bool) {
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			histogram, ok := m.Data.(metricdata.Histogram[N])
+			if !assert.True(t, ok, "%s is not histogram", name) {
+				return point, false
+			}
+			for _, dp := range histogram.DataPoints {
+				value, _ := dp.Attributes.Value("db.operation.name")
+				if value.AsString() == operation {
+					return dp, true
+				}
+			}
+		}
+	}
+	return point, false
+}
+
+func TestTelemetryAgainstHttpMock(t *testing.T) {
+	mockTransport := httpmock.NewMockTransport()
+	mockTransport.RegisterResponder(http.MethodGet, DefaultEndpoint+"/-/healthy",
+		httpmock.NewStringResponder(http.StatusOK, "OK"))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query_range`,
+		httpmock.NewStringResponder(http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"job":"a"},"values":[[1734677495,"1"],[1734677555,"1"]]},
+			{"metric":{"job":"b"},"values":[[1734677495,"1"],[1734677555,"1"]]},
+			{"metric":{"job":"c"},"values":[[1734677495,"1"],[1734677555,"1"]]}]}}`))
+	mockTransport.RegisterResponder(http.MethodGet, `=~^`+DefaultEndpoint+`/prometheus/api/v1/query\?`,
+		httpmock.NewStringResponder(http.StatusUnprocessableEntity,
+			`{"status":"error","errorType":"422","error":"cannot parse query"}`))
+
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client, err := New(t.Context(), Config{
+		Address:        DefaultEndpoint,
+		HttpClient:     &http.Client{Transport: mockTransport},
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	data, err := client.Range(t.Context(), "up", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, data, 3)
+	_, err = client.Instant(t.Context(), "up{", time.Now(), time.Minute)
+	assert.ErrorIs(t, err, ErrQueryError)
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"ping", "range", "instant"}, names)
+
+	var collected metricdata.ResourceMetrics
+	err = reader.Collect(t.Context(), &collected)
+	if err != nil {
+		t.Errorf("error collecting metrics: %s", err)
+		return
+	}
+	duration, found := histogramPoint[float64](t, collected, "db.client.operation.duration", "range")
+	if assert.True(t, found, "duration of range is not recorded") {
+		assert.Equal(t, uint64(1), duration.Count)
+		assert.False(t, duration.Attributes.HasValue("error.type"))
+		assert.Equal(t, attribute.StringValue("Victoria Metrics"), valueOf(duration.Attributes, "db.system.name"))
+		assert.Equal(t, attribute.StringValue("127.0.0.1"), valueOf(duration.Attributes, "server.address"))
+	}
+	duration, found = histogramPoint[float64](t, collected, "db.client.operation.duration", "instant")
+	if assert.True(t, found, "duration of instant is not recorded") {
+		assert.Equal(t, attribute.StringValue("422"), valueOf(duration.Attributes, "error.type"))
+		assert.Equal(t, attribute.StringValue("422"), valueOf(duration.Attributes, "db.response.status_code"))
+	}
+	rows, found := histogramPoint[int64](t, collected, "db.client.response.returned_rows", "range")
+	if assert.True(t, found, "series returned are not recorded") {
+		assert.Equal(t, int64(3), rows.Sum)
+	}
+	_, found = histogramPoint[int64](t, collected, "db.client.response.returned_rows", "instant")
+	assert.False(t, found, "series are recorded for failed query")
+	size, found := histogramPoint[int64](t, collected, "http.client.response.body.size", "range")
+	if assert.True(t, found, "response size is not recorded") {
+		assert.Greater(t, size.Sum, int64(100))
+	}
+}
+
+func valueOf(set attribute.Set, key attribute.Key) attribute.Value {
+	value, _ := set.Value(key)
+	return value
+}
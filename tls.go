@@ -0,0 +1,212 @@
+package vmclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// TLSConfig defines TLS settings of connections to database
+type TLSConfig struct {
+	// CAFile is path to PEM file with certificate authorities, which server certificates are verified with.
+	// File is read again, when it is modified, so rotated certificates are used without restart, also for
+	// connections made through proxy, which require ServerName, if address of server is ip address.
+	CAFile string
+	// RootCAs is pool of certificate authorities used instead of CAFile, if set
+	RootCAs *x509.CertPool
+	// CertFile and KeyFile are paths to PEM files with client certificate and its key used for mutual TLS.
+	// Files are read again, when they are modified.
+	CertFile string
+	KeyFile  string
+	// ServerName is name of server used to verify its certificate, host of address is used if empty
+	ServerName string
+	// MinVersion is minimal version of TLS, like tls.VersionTLS13, tls.VersionTLS12 is used if zero
+	MinVersion uint16
+	// InsecureSkipVerify disables verification of server certificate
+	InsecureSkipVerify bool
+}
+
+// reloadingFile keeps value parsed from files, parsing them again, when any of them is modified
+type reloadingFile[T any] struct {
+	paths []string
+	parse func() (T, error)
+
+	mu       sync.Mutex
+	value    T
+	modTimes []time.Time
+}
+
+func newReloadingFile[T any](parse func() (T, error), paths ...string) *reloadingFile[T] {
+	return &reloadingFile[T]{paths: paths, parse: parse}
+}
+
+// get returns value parsed from files, previous value is kept, if files are broken in the middle of rotation
+func (f *reloadingFile[T]) get() (value T, err error) {
+	modTimes := make([]time.Time, len(f.paths))
+	for i := range f.paths {
+		info, errStat := os.Stat(f.paths[i])
+		if errStat != nil {
+			return value, errStat
+		}
+		modTimes[i] = info.ModTime()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.modTimes != nil && slices.EqualFunc(f.modTimes, modTimes, time.Time.Equal) {
+		return f.value, nil
+	}
+	value, err = f.parse()
+	if err != nil {
+		if f.modTimes != nil {
+			return f.value, nil
+		}
+		return value, err
+	}
+	f.value = value
+	f.modTimes = modTimes
+	return value, nil
+}
+
+// build makes tls.Config, which reloads client certificate from files, when they are rotated.
+// Pool of certificate authorities reloaded from CAFile is returned, if it is used.
+func (cfg TLSConfig) build() (tlsConfig *tls.Config, pool *reloadingFile[*x509.CertPool], err error) {
+	tlsConfig = &tls.Config{
+		ServerName:         cfg.ServerName,
+		MinVersion:         cfg.MinVersion,
+		RootCAs:            cfg.RootCAs,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, nil, errors.New("both certificate and key files are required for mutual TLS")
+	}
+	if cfg.CertFile != "" {
+		cert := newReloadingFile(func() (*tls.Certificate, error) {
+			loaded, errLoad := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+			if errLoad != nil {
+				return nil, fmt.Errorf("error loading client certificate: %w", errLoad)
+			}
+			return &loaded, nil
+		}, cfg.CertFile, cfg.KeyFile)
+		_, err = cert.get()
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert.get()
+		}
+	}
+	if cfg.CAFile != "" && cfg.RootCAs == nil {
+		pool = newReloadingFile(func() (*x509.CertPool, error) {
+			pem, errRead := os.ReadFile(cfg.CAFile)
+			if errRead != nil {
+				return nil, errRead
+			}
+			loaded := x509.NewCertPool()
+			if !loaded.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates are found in %s", cfg.CAFile)
+			}
+			return loaded, nil
+		}, cfg.CAFile)
+		tlsConfig.RootCAs, err = pool.get()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading certificate authorities: %w", err)
+		}
+		if !cfg.InsecureSkipVerify {
+			// certificate authorities are fixed in RootCAs, so built-in verification is replaced by one
+			// using pool reloaded, when file is rotated, whichever way connection is made
+			tlsConfig.InsecureSkipVerify = true
+			tlsConfig.VerifyConnection = verifyConnection(pool, cfg.ServerName)
+		}
+	}
+	return tlsConfig, pool, nil
+}
+
+// verifyConnection verifies certificate of server with certificate authorities loaded from pool.
+// Server name indicated by connection is used, if serverName is empty.
+func verifyConnection(pool *reloadingFile[*x509.CertPool], serverName string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		roots, err := pool.get()
+		if err != nil {
+			return fmt.Errorf("error loading certificate authorities: %w", err)
+		}
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("server has not provided certificate")
+		}
+		name := serverName
+		if name == "" {
+			name = state.ServerName
+		}
+		if name == "" {
+			// server name is not indicated for ip addresses, so it can not be known behind proxy
+			return errors.New("server name is required to verify certificate of server, set it in TLSConfig")
+		}
+		options := x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       name,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, intermediate := range state.PeerCertificates[1:] {
+			options.Intermediates.AddCert(intermediate)
+		}
+		_, err = state.PeerCertificates[0].Verify(options)
+		return err
+	}
+}
+
+// newHTTPClient makes client with dedicated transport, so TLS settings do not affect other clients of process.
+// Query strings of urls are hidden from spans of transport, if redaction policy requires.
+func newHTTPClient(cfg TLSConfig, redaction RedactionPolicy, opts ...otelhttp.Option) (*http.Client, error) {
+	tlsConfig, pool, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if pool != nil && !cfg.InsecureSkipVerify {
+		// server name is not indicated for ip addresses, so connections made directly are verified
+		// against host of address they are dialed to
+		transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			connConfig := tlsConfig.Clone()
+			if connConfig.ServerName == "" {
+				connConfig.ServerName, _, _ = net.SplitHostPort(addr)
+			}
+			connConfig.VerifyConnection = verifyConnection(pool, connConfig.ServerName)
+			dialer := tls.Dialer{Config: connConfig}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+	if !redaction.RedactQuery {
+		return &http.Client{Transport: instrumentedTransport{
+			RoundTripper: otelhttp.NewTransport(transport, opts...),
+			base:         transport,
+		}}, nil
+	}
+	return &http.Client{Transport: instrumentedTransport{
+		RoundTripper: queryHidingTransport{otelhttp.NewTransport(queryRestoringTransport{transport}, opts...)},
+		base:         transport,
+	}}, nil
+}
+
+// instrumentedTransport traces requests, keeping ability of Client.Close to close idle connections,
+// which otelhttp.Transport does not provide
+type instrumentedTransport struct {
+	http.RoundTripper
+	base *http.Transport
+}
+
+func (t instrumentedTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
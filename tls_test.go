@@ -0,0 +1,187 @@
+package vmclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCA(t *testing.T, path string, cert *x509.Certificate, modTime time.Time) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	err := os.WriteFile(path, data, 0o600)
+	if err == nil {
+		err = os.Chtimes(path, modTime, modTime)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// selfSignedCertificate makes certificate authority, which has not issued certificate of test server
+func selfSignedCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestTLSAgainstTestServer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeCA(t, caFile, server.Certificate(), time.Now().Add(-time.Hour))
+	client, err := New(t.Context(), Config{
+		Address: server.URL,
+		TLS:     TLSConfig{CAFile: caFile, MinVersion: tls.VersionTLS12},
+	})
+	if err != nil {
+		t.Errorf("error creating client: %s", err)
+		return
+	}
+	assertDefaultTransportIntact(t)
+
+	writeCA(t, caFile, selfSignedCertificate(t), time.Now().Add(-time.Minute))
+	client.hclient.CloseIdleConnections()
+	assert.Error(t, client.Ping(t.Context()), "rotated certificate authority is not used")
+
+	writeCA(t, caFile, server.Certificate(), time.Now())
+	client.hclient.CloseIdleConnections()
+	assert.NoError(t, client.Ping(t.Context()))
+
+	_, err = New(t.Context(), Config{Address: server.URL})
+	assert.Error(t, err, "certificate of unknown authority is accepted")
+	_, err = New(t.Context(), Config{Address: server.URL, Insecure: true})
+	assert.NoError(t, err)
+	assertDefaultTransportIntact(t)
+
+	_, err = New(t.Context(), Config{Address: server.URL, TLS: TLSConfig{CertFile: caFile}})
+	assert.Error(t, err, "certificate without key is accepted")
+}
+
+func TestTLSThroughProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	// proxy tunnels connections to any host to test server, which certificate is issued for example.com
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeCA(t, caFile, server.Certificate(), time.Now().Add(-time.Hour))
+	client, err := newHTTPClient(TLSConfig{CAFile: caFile}, RedactionPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Transport.(instrumentedTransport).base.Proxy = http.ProxyURL(proxyURL)
+	getHost := func(host string) error {
+		resp, errGet := client.Get("https://" + host + "/-/healthy")
+		if errGet != nil {
+			return errGet
+		}
+		return resp.Body.Close()
+	}
+	get := func() error {
+		client.CloseIdleConnections()
+		return getHost("example.com")
+	}
+	assert.NoError(t, get())
+
+	// every host is verified against its own name, while handshakes are made concurrently
+	hosts := []string{"example.com", "vm.example.com", "vmselect.example.com", "vminsert.example.com", "other.org"}
+	errs := make([]error, len(hosts))
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = getHost(hosts[i])
+		}()
+	}
+	wg.Wait()
+	for i := range hosts[:4] {
+		assert.NoError(t, errs[i], hosts[i])
+	}
+	assert.Error(t, errs[4], "certificate is verified against name of other host")
+
+	writeCA(t, caFile, selfSignedCertificate(t), time.Now().Add(-time.Minute))
+	assert.Error(t, get(), "rotated certificate authority is not used through proxy")
+
+	writeCA(t, caFile, server.Certificate(), time.Now())
+	assert.NoError(t, get())
+}
+
+func assertDefaultTransportIntact(t *testing.T) {
+	t.Helper()
+	tlsConfig := http.DefaultTransport.(*http.Transport).TLSClientConfig
+	if tlsConfig != nil {
+		assert.False(t, tlsConfig.InsecureSkipVerify, "default transport is modified")
+	}
+}